@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/config"
+	"github.com/Jevs21/cctop/internal/hub"
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// tokenEnv names the environment variable that supplies the hub token when
+// --token is not given, keeping it out of process listings.
+const tokenEnv = "CCTOP_TOKEN"
+
+// defaultHubAddr is the address `cctop hub` listens on by default.
+const defaultHubAddr = ":7433"
+
+// requireToken returns the token from --token or the environment.
+func requireToken(token string) (string, error) {
+	if token == "" {
+		token = os.Getenv(tokenEnv)
+	}
+	if token == "" {
+		return "", fmt.Errorf("a shared token is required (--token or %s)", tokenEnv)
+	}
+	return token, nil
+}
+
+// warnInsecureHub warns when the hub URL sends the token in the clear to
+// another machine.
+func warnInsecureHub(hubURL string) {
+	if hub.InsecureURL(hubURL) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is plain HTTP; the token and sessions cross the network unencrypted (use an https:// hub)\n", hubURL)
+	}
+}
+
+// runHub implements `cctop hub`: collect snapshots pushed by agents and serve
+// the merged view to `cctop --hub` clients.
+func runHub(args []string) error {
+	flags := flag.NewFlagSet("hub", flag.ExitOnError)
+	listen := flags.String("listen", defaultHubAddr, "Address to listen on")
+	token := flags.String("token", "", "Shared token agents and clients must present (default $"+tokenEnv+")")
+	tlsCert := flags.String("tls-cert", "", "Serve HTTPS with this PEM certificate (requires --tls-key)")
+	tlsKey := flags.String("tls-key", "", "PEM private key of --tls-cert")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop hub [OPTIONS]\n\n")
+		fmt.Fprintf(os.Stderr, "Collect sessions pushed by cctop agents and serve them to cctop --hub.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --listen ADDR Address to listen on (default %s)\n", defaultHubAddr)
+		fmt.Fprintf(os.Stderr, "  --token T     Shared token agents and clients must present\n")
+		fmt.Fprintf(os.Stderr, "                (default $%s)\n", tokenEnv)
+		fmt.Fprintf(os.Stderr, "  --tls-cert FILE\n")
+		fmt.Fprintf(os.Stderr, "                Serve HTTPS with this PEM certificate\n")
+		fmt.Fprintf(os.Stderr, "  --tls-key FILE\n")
+		fmt.Fprintf(os.Stderr, "                PEM private key of --tls-cert\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	secret, err := requireToken(*token)
+	if err != nil {
+		return err
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           hub.NewServer(secret),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	if *tlsCert != "" {
+		log.Printf("cctop hub listening on %s (HTTPS)", *listen)
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		if !hub.LoopbackAddr(*listen) {
+			log.Printf("warning: serving plain HTTP on %s; the token and sessions cross the network unencrypted (use --tls-cert/--tls-key or a TLS proxy)", *listen)
+		}
+		log.Printf("cctop hub listening on %s", *listen)
+		err = server.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// runAgent implements `cctop agent`: push this machine's sessions to a hub
+// until interrupted.
+func runAgent(args []string) error {
+	flags := flag.NewFlagSet("agent", flag.ExitOnError)
+	hubURL := flags.String("hub", "", "URL of the hub to push to (e.g. http://monitor:7433)")
+	token := flags.String("token", "", "Shared token of the hub (default $"+tokenEnv+")")
+	hostname, _ := os.Hostname()
+	name := flags.String("name", hostname, "Name this machine reports as")
+	interval := flags.Duration("interval", 2*time.Second, "Time between pushes")
+	recentWindow := flags.Duration("recent", 0, "Also push sessions that exited within this window (e.g. 30m)")
+	var claudeDirs stringList
+	flags.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+	allUsers := flags.Bool("all-users", false, "Also push other users' sessions")
+	configPath := flags.String("config", config.DefaultPath(), "Path to the config file")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop agent --hub URL [OPTIONS]\n\n")
+		fmt.Fprintf(os.Stderr, "Push this machine's sessions to a cctop hub.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --hub URL     Hub to push to (e.g. http://monitor:7433)\n")
+		fmt.Fprintf(os.Stderr, "  --token T     Shared token of the hub (default $%s)\n", tokenEnv)
+		fmt.Fprintf(os.Stderr, "  --name NAME   Name this machine reports as (default %s)\n", hostname)
+		fmt.Fprintf(os.Stderr, "  --interval D  Time between pushes (default 2s)\n")
+		fmt.Fprintf(os.Stderr, "  --recent DUR  Also push sessions that exited within DUR (e.g. 30m)\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --all-users   Also push other users' sessions\n")
+		fmt.Fprintf(os.Stderr, "  --config FILE Path to the config file, for IDE mappings\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *hubURL == "" {
+		flags.Usage()
+		return fmt.Errorf("--hub is required")
+	}
+	if *name == "" {
+		return fmt.Errorf("--name is required when the hostname is unknown")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	secret, err := requireToken(*token)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return err
+	}
+	registerIDEs(cfg.IDEs)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	warnInsecureHub(*hubURL)
+	log.Printf("cctop agent pushing to %s as %s", *hubURL, *name)
+	sampler := process.NewSampler()
+	agent := hub.Agent{
+		HubURL:   *hubURL,
+		Token:    secret,
+		Name:     *name,
+		Interval: *interval,
+		Discover: func() []session.Session {
+			sessions := session.Discover(session.DiscoverOptions{RecentWindow: *recentWindow, ClaudeDirs: claudeDirs, AllUsers: *allUsers})
+			if table, err := sampler.Sample(time.Now()); err == nil {
+				session.AttachResources(sessions, table)
+			}
+			return sessions
+		},
+		Logf: log.Printf,
+	}
+	return agent.Run(ctx)
+}
@@ -4,29 +4,168 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Jevs21/cctop/internal/config"
+	"github.com/Jevs21/cctop/internal/grouping"
+	"github.com/Jevs21/cctop/internal/session"
 	"github.com/Jevs21/cctop/internal/tui"
 )
 
+// subcommands maps subcommand names to their entry points.
+var subcommands = map[string]func(args []string) error{
+	"agent":    runAgent,
+	"hub":      runHub,
+	"report":   runReport,
+	"resume":   runResume,
+	"search":   runSearch,
+	"sessions": runSessions,
+}
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// registerIDEs installs the config file's IDE mappings for discovery and
+// display.
+func registerIDEs(ides []config.IDE) {
+	mappings := make([]session.IDE, len(ides))
+	for i, ide := range ides {
+		mappings[i] = session.IDE{Match: ide.Match, Name: ide.Name, Icon: ide.Icon, Color: ide.Color}
+	}
+	session.RegisterIDEs(mappings)
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			if err := subcommand(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	onceMode := flag.Bool("once", false, "Print the table once and exit (no live refresh)")
 	debugMode := flag.Bool("debug", false, "Print timing diagnostics to stderr")
+	recentWindow := flag.Duration("recent", 0, "Also list sessions that exited within this window (e.g. 30m)")
+	filterQuery := flag.String("filter", "", "Only show sessions matching this query (e.g. 'state:waiting age>10m')")
+	format := flag.String("format", "table", "Output format: table or json")
+	sortSpec := flag.String("sort", "", "Sort keys, e.g. state,-in_state,project (- for descending)")
+	groupName := flag.String("group", "", "Group rows by project, repo, branch, source or state")
+	viewName := flag.String("view", "", "Start in the named saved view from the config file")
+	configPath := flag.String("config", config.DefaultPath(), "Path to the config file")
+	var hosts stringList
+	flag.Var(&hosts, "host", "Also monitor sessions on this ssh target (repeatable)")
+	remoteCommand := flag.String("remote-command", "cctop", "Command that runs cctop on --host targets")
+	var claudeDirs stringList
+	flag.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+	allUsers := flag.Bool("all-users", false, "Also list other users' sessions, with a USER column")
+	hubURL := flag.String("hub", "", "Also show sessions collected by the cctop hub at this URL")
+	token := flag.String("token", "", "Shared token of the --hub (default $"+tokenEnv+")")
 
 	// Support -1 as an alias for --once
 	flag.BoolVar(onceMode, "1", false, "Alias for --once")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "cctop — Claude Session Monitor\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: cctop [OPTIONS]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: cctop [OPTIONS]\n")
+		fmt.Fprintf(os.Stderr, "       cctop agent --hub URL [OPTIONS]\n")
+		fmt.Fprintf(os.Stderr, "       cctop hub [OPTIONS]\n")
+		fmt.Fprintf(os.Stderr, "       cctop report [OPTIONS]\n")
+		fmt.Fprintf(os.Stderr, "       cctop resume [OPTIONS] <query>\n")
+		fmt.Fprintf(os.Stderr, "       cctop search [OPTIONS] <query>\n")
+		fmt.Fprintf(os.Stderr, "       cctop sessions [OPTIONS] [query]\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		fmt.Fprintf(os.Stderr, "  --once, -1    Print the table once and exit (no live refresh)\n")
 		fmt.Fprintf(os.Stderr, "  --debug       Print timing diagnostics to stderr\n")
+		fmt.Fprintf(os.Stderr, "  --recent DUR  Also list sessions that exited within DUR (e.g. 30m)\n")
+		fmt.Fprintf(os.Stderr, "  --filter Q    Only show sessions matching query Q (e.g. 'state:waiting age>10m')\n")
+		fmt.Fprintf(os.Stderr, "  --format FMT  Output format: table (default) or json; json without\n")
+		fmt.Fprintf(os.Stderr, "                --once streams one snapshot per line every refresh\n")
+		fmt.Fprintf(os.Stderr, "  --sort KEYS   Sort keys, e.g. state,-in_state,project (- for descending)\n")
+		fmt.Fprintf(os.Stderr, "  --group BY    Group rows by project, repo, branch, source or state\n")
+		fmt.Fprintf(os.Stderr, "  --view NAME   Start in the named saved view from the config file\n")
+		fmt.Fprintf(os.Stderr, "  --config FILE Path to the config file (default %s)\n", config.DefaultPath())
+		fmt.Fprintf(os.Stderr, "  --host TARGET Also monitor sessions on ssh target TARGET (user@box);\n")
+		fmt.Fprintf(os.Stderr, "                repeat for several hosts\n")
+		fmt.Fprintf(os.Stderr, "  --remote-command CMD\n")
+		fmt.Fprintf(os.Stderr, "                Command that runs cctop on remote hosts (default cctop)\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR for sessions\n")
+		fmt.Fprintf(os.Stderr, "                started with CLAUDE_CONFIG_DIR; repeat for several\n")
+		fmt.Fprintf(os.Stderr, "  --all-users   Also list other users' sessions, with a USER column;\n")
+		fmt.Fprintf(os.Stderr, "                their topics need read access to their ~/.claude\n")
+		fmt.Fprintf(os.Stderr, "  --hub URL     Also show sessions collected by the cctop hub at URL\n")
+		fmt.Fprintf(os.Stderr, "  --token T     Shared token of the hub (default $%s)\n", tokenEnv)
 		fmt.Fprintf(os.Stderr, "  -h, --help    Show usage information\n")
 	}
 
 	flag.Parse()
 
-	if err := tui.Run(*onceMode, *debugMode); err != nil {
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (want table or json)\n", *format)
+		os.Exit(2)
+	}
+
+	if *hubURL != "" {
+		secret, err := requireToken(*token)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		*token = secret
+		warnInsecureHub(*hubURL)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	registerIDEs(cfg.IDEs)
+
+	sortKeys, err := tui.ParseSortSpec(*sortSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	groupBy, err := grouping.Parse(*groupName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	opts := tui.Options{
+		Once:         *onceMode,
+		Debug:        *debugMode,
+		RecentWindow: *recentWindow,
+		Filter:       *filterQuery,
+		Format:       *format,
+		Sort:         sortKeys,
+		Views:        cfg.Views,
+		View:         *viewName,
+		Group:        groupBy,
+
+		Hosts:         hosts,
+		RemoteCommand: *remoteCommand,
+		ClaudeDirs:    claudeDirs,
+		AllUsers:      *allUsers,
+		Hub:           *hubURL,
+		Token:         *token,
+	}
+
+	if err := tui.Run(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/report"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// runReport implements `cctop report`: aggregate usage statistics over all
+// transcripts active within a time window.
+func runReport(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	since := flags.String("since", "7d", "Report on activity since this long ago (e.g. 7d, 12h) or a date (2025-01-31)")
+	format := flags.String("format", "table", "Output format: table, json, csv or markdown")
+	top := flags.Int("top", 15, "Number of tools to list (0 = all)")
+	var claudeDirs stringList
+	flags.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop report [OPTIONS]\n\n")
+		fmt.Fprintf(os.Stderr, "Summarize sessions, tokens, estimated cost and tool use across all transcripts.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --since WHEN  Activity since this long ago (default 7d; e.g. 12h) or a date (2025-01-31)\n")
+		fmt.Fprintf(os.Stderr, "  --format FMT  Output format: table (default), json, csv or markdown\n")
+		fmt.Fprintf(os.Stderr, "  --top N       Number of tools to list (0 = all, default 15)\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR; repeat for several\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !slices.Contains(report.Formats, *format) {
+		return fmt.Errorf("unknown format %q (want %s)", *format, strings.Join(report.Formats, ", "))
+	}
+
+	now := time.Now()
+	cutoff, err := report.ParseSince(*since, now)
+	if err != nil {
+		return err
+	}
+
+	result, err := report.Build(session.ConfigDirs(claudeDirs), cutoff, now)
+	if err != nil {
+		return err
+	}
+	if *top > 0 && len(result.Tools) > *top {
+		result.Tools = result.Tools[:*top]
+	}
+
+	return report.Write(os.Stdout, result, *format)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// maxResumeListEntries caps the number of matches printed by --list.
+const maxResumeListEntries = 10
+
+// runResume implements `cctop resume <query>`: fuzzy-match a past session by
+// topic or project and reopen it with claude --resume.
+func runResume(args []string) error {
+	flags := flag.NewFlagSet("resume", flag.ExitOnError)
+	printOnly := flags.Bool("print", false, "Print the resume command instead of running it")
+	listOnly := flags.Bool("list", false, "List matching sessions instead of resuming")
+	var claudeDirs stringList
+	flags.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop resume [OPTIONS] <query>\n\n")
+		fmt.Fprintf(os.Stderr, "Fuzzy-match a past session by topic, project, path or branch and resume it.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --print       Print the resume command instead of running it\n")
+		fmt.Fprintf(os.Stderr, "  --list        List matching sessions instead of resuming\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR; repeat for several\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	query := strings.Join(flags.Args(), " ")
+	matches := session.MatchHistory(session.ScanHistory(session.ConfigDirs(claudeDirs)...), query)
+	if len(matches) == 0 {
+		return fmt.Errorf("no session matches %q", query)
+	}
+
+	if *listOnly {
+		for i, entry := range matches {
+			if i >= maxResumeListEntries {
+				break
+			}
+			fmt.Printf("%s  %-8s  %-24s  %s\n", entry.Modified.Format("2006-01-02 15:04"), entry.SessionID[:min(8, len(entry.SessionID))], entry.Project, entry.Topic)
+		}
+		return nil
+	}
+
+	best := matches[0]
+	if *printOnly {
+		fmt.Println(best.ResumeCommand())
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Resuming %s: %s\n", best.Project, best.Topic)
+
+	claudePath, err := exec.LookPath("claude")
+	if err != nil {
+		return fmt.Errorf("claude not found in PATH")
+	}
+	if best.CWD != "" {
+		if err := os.Chdir(best.CWD); err != nil {
+			return err
+		}
+	}
+	return syscall.Exec(claudePath, []string{"claude", "--resume", best.SessionID}, os.Environ())
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Jevs21/cctop/internal/search"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// runSearch implements `cctop search <query>`: full-text search over all
+// transcripts using the on-disk cache.
+func runSearch(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := flags.Int("limit", 50, "Maximum number of matches to print (0 = all)")
+	var claudeDirs stringList
+	flags.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop search [OPTIONS] <query>\n\n")
+		fmt.Fprintf(os.Stderr, "Search prompts, replies, and tool inputs across all transcripts.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --limit N     Maximum number of matches to print (0 = all)\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR; repeat for several\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	query := strings.Join(flags.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		flags.Usage()
+		return fmt.Errorf("missing search query")
+	}
+
+	cache := search.Open(search.DefaultPath())
+	changed, err := cache.Update(session.ConfigDirs(claudeDirs)...)
+	if err != nil {
+		return err
+	}
+	if changed {
+		if err := cache.Save(); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range cache.Search(query, *limit) {
+		snippet := result.Snippet
+		if runes := []rune(snippet); len(runes) > 120 {
+			snippet = string(runes[:119]) + "…"
+		}
+		fmt.Printf("%s  %-8s  %-24s  %-10s  %s\n",
+			result.Timestamp.Local().Format("2006-01-02 15:04"),
+			result.SessionID[:min(8, len(result.SessionID))],
+			result.Project, result.Kind, snippet)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Jevs21/cctop/internal/tui"
+)
+
+// runSessions implements `cctop sessions [query]`: browse every transcript
+// under ~/.claude/projects, live or not.
+func runSessions(args []string) error {
+	flags := flag.NewFlagSet("sessions", flag.ExitOnError)
+	onceMode := flags.Bool("once", false, "Print the session list once and exit")
+	sortName := flags.String("sort", "modified", "Sort by modified, created, messages, size or project")
+	flags.BoolVar(onceMode, "1", false, "Alias for --once")
+	var claudeDirs stringList
+	flags.Var(&claudeDirs, "claude-dir", "Also search this Claude config directory (repeatable)")
+
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: cctop sessions [OPTIONS] [query]\n\n")
+		fmt.Fprintf(os.Stderr, "Browse all historical sessions across all projects.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --once, -1    Print the session list once and exit\n")
+		fmt.Fprintf(os.Stderr, "  --sort FIELD  Sort by modified, created, messages, size or project\n")
+		fmt.Fprintf(os.Stderr, "  --claude-dir DIR\n")
+		fmt.Fprintf(os.Stderr, "                Also search Claude config directory DIR; repeat for several\n")
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	historySort, err := tui.ParseHistorySort(*sortName)
+	if err != nil {
+		return err
+	}
+
+	return tui.Run(tui.Options{
+		Once:         *onceMode,
+		History:      true,
+		HistoryQuery: strings.Join(flags.Args(), " "),
+		HistorySort:  historySort,
+		ClaudeDirs:   claudeDirs,
+	})
+}
@@ -0,0 +1,172 @@
+// Package activity records how fast sessions grow, minute by minute, and
+// draws the series as sparklines and bar graphs.
+package activity
+
+import (
+	"strings"
+	"time"
+)
+
+// Minutes is how many minutes of per-session activity a Tracker keeps.
+const Minutes = 60
+
+// levels are the bar glyphs from lowest to highest.
+var levels = []rune("▁▂▃▄▅▆▇█")
+
+// Metric selects which counter a series is drawn from.
+type Metric int
+
+const (
+	Tokens Metric = iota // Tokens used
+	Lines                // Transcript lines written
+)
+
+// Sample is one session's counters at a refresh.
+type Sample struct {
+	Key    string // Identifies the session across refreshes
+	Lines  int    // Complete transcript lines
+	Tokens int    // Total tokens used
+}
+
+// bucket is the activity observed during one minute.
+type bucket struct {
+	lines  int
+	tokens int
+}
+
+// history records a session's growth per minute, as observed across
+// refreshes since tracking began.
+type history struct {
+	since   time.Time        // First sample; earlier minutes are unknown
+	lines   int              // Line count at the last sample
+	tokens  int              // Token total at the last sample
+	buckets map[int64]bucket // Keyed by Unix minute
+}
+
+// Tracker turns successive samples into per-minute activity. The zero value
+// is ready to use.
+type Tracker struct {
+	histories map[string]*history
+}
+
+// Track adds the growth in lines and tokens since the previous samples to
+// the current minute's bucket, dropping sessions missing from samples and
+// minutes older than Minutes. A counter that went backwards (a rewritten
+// transcript) starts a new baseline.
+func (t *Tracker) Track(now time.Time, samples []Sample) {
+	minute := now.Unix() / 60
+	next := make(map[string]*history, len(samples))
+	for _, sample := range samples {
+		h, ok := t.histories[sample.Key]
+		if !ok {
+			h = &history{since: now, buckets: make(map[int64]bucket)}
+		} else if sample.Lines >= h.lines && sample.Tokens >= h.tokens {
+			b := h.buckets[minute]
+			b.lines += sample.Lines - h.lines
+			b.tokens += sample.Tokens - h.tokens
+			h.buckets[minute] = b
+		}
+		h.lines = sample.Lines
+		h.tokens = sample.Tokens
+
+		for bucketMinute := range h.buckets {
+			if bucketMinute <= minute-Minutes {
+				delete(h.buckets, bucketMinute)
+			}
+		}
+		next[sample.Key] = h
+	}
+	t.histories = next
+}
+
+// Tracked reports whether key was in the latest samples.
+func (t *Tracker) Tracked(key string) bool {
+	_, ok := t.histories[key]
+	return ok
+}
+
+// Series returns key's per-minute activity over the minutes up to now,
+// oldest first. Minutes before tracking began are -1.
+func (t *Tracker) Series(key string, now time.Time, minutes int, metric Metric) []int {
+	series := make([]int, minutes)
+	h, ok := t.histories[key]
+	current := now.Unix() / 60
+	for i := range series {
+		minute := current - int64(minutes-1-i)
+		if !ok || minute < h.since.Unix()/60 {
+			series[i] = -1
+			continue
+		}
+		b := h.buckets[minute]
+		if metric == Tokens {
+			series[i] = b.tokens
+		} else {
+			series[i] = b.lines
+		}
+	}
+	return series
+}
+
+// Peak returns the largest value in series, or 0.
+func Peak(series []int) int {
+	peak := 0
+	for _, value := range series {
+		peak = max(peak, value)
+	}
+	return peak
+}
+
+// Sparkline renders one glyph per value scaled to the series maximum. Zero
+// minutes show the lowest bar so a stalled session reads as a flat line;
+// unknown minutes are blank.
+func Sparkline(series []int) string {
+	peak := Peak(series)
+
+	var b strings.Builder
+	for _, value := range series {
+		switch {
+		case value < 0:
+			b.WriteRune(' ')
+		case value == 0 || peak == 0:
+			b.WriteRune(levels[0])
+		default:
+			// Any activity shows at least the second level
+			level := 1 + (value*(len(levels)-1)-1)/peak
+			b.WriteRune(levels[min(level, len(levels)-1)])
+		}
+	}
+	return b.String()
+}
+
+// Graph renders a multi-row bar graph of series, height rows tall, each row
+// resolving eight levels. The bottom row marks idle minutes with a dot.
+func Graph(series []int, height int) []string {
+	peak := Peak(series)
+
+	steps := height * len(levels)
+	rows := make([]string, height)
+	for row := range rows {
+		var b strings.Builder
+		floor := (height - 1 - row) * len(levels) // Steps below this row
+		for _, value := range series {
+			filled := 0
+			if value > 0 && peak > 0 {
+				filled = max(1, value*steps/peak)
+			}
+			switch {
+			case value < 0:
+				b.WriteRune(' ')
+			case filled >= floor+len(levels):
+				b.WriteRune(levels[len(levels)-1])
+			case filled > floor:
+				b.WriteRune(levels[filled-floor-1])
+			case row == height-1:
+				b.WriteRune('·')
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		rows[row] = b.String()
+	}
+	return rows
+}
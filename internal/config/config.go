@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config holds user settings loaded from the config file.
+type Config struct {
+	Views []View `json:"views"`
+	IDEs  []IDE  `json:"ides,omitempty"`
+}
+
+// View is a named saved view: a filter query, sort keys, visible columns and
+// grouping. Empty fields fall back to the defaults.
+type View struct {
+	Name    string   `json:"name"`
+	Filter  string   `json:"filter,omitempty"`  // Filter query, e.g. "state:waiting age>10m"
+	Sort    []string `json:"sort,omitempty"`    // Column IDs, "-" prefix for descending, e.g. ["state", "-in_state", "project"]
+	Columns []string `json:"columns,omitempty"` // Column IDs in display order
+	Group   string   `json:"group,omitempty"`   // Grouping: project, repo, branch, source or state
+}
+
+// IDE maps lock-file ideName values the built-in registry does not know,
+// or styles differently, to a short source name.
+type IDE struct {
+	Match string `json:"match"`           // Case-insensitive leading words of the ideName, e.g. "Sublime Text"
+	Name  string `json:"name"`            // Short name for the SRC column, e.g. "Sublime"
+	Icon  string `json:"icon,omitempty"`  // Glyph shown before the name
+	Color string `json:"color,omitempty"` // ANSI 256 colour number or #rrggbb
+}
+
+// DefaultPath returns the location of the config file:
+// $XDG_CONFIG_HOME/cctop/config.json, or the platform equivalent.
+func DefaultPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "cctop", "config.json")
+}
+
+// Load reads the config file at path. A missing or empty file yields an
+// empty config.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &cfg, nil
+	}
+	if unmarshalErr := json.Unmarshal(data, &cfg); unmarshalErr != nil {
+		return nil, fmt.Errorf("%s: %w", path, unmarshalErr)
+	}
+
+	for i, view := range cfg.Views {
+		if view.Name == "" {
+			return nil, fmt.Errorf("%s: view %d has no name", path, i+1)
+		}
+	}
+	for i, ide := range cfg.IDEs {
+		if ide.Match == "" || ide.Name == "" {
+			return nil, fmt.Errorf("%s: ide %d needs both match and name", path, i+1)
+		}
+	}
+
+	return &cfg, nil
+}
+
+// FindView returns the view with the given name.
+func (c *Config) FindView(name string) (View, bool) {
+	for _, view := range c.Views {
+		if view.Name == name {
+			return view, true
+		}
+	}
+	return View{}, false
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Repo locates a git working tree and its git directories.
+type Repo struct {
+	Root      string // Top-level directory of the working tree
+	GitDir    string // Git directory of this working tree (.git, or .git/worktrees/<name>)
+	CommonDir string // Git directory shared by all worktrees of the repository
+	Worktree  string // Name of a linked worktree; empty for the main working tree
+}
+
+// FindRepo walks up from dir to the nearest directory containing .git. A
+// .git directory marks a main working tree; a .git file ("gitdir: <path>")
+// marks a linked worktree or submodule.
+func FindRepo(dir string) (Repo, bool) {
+	if dir == "" {
+		return Repo{}, false
+	}
+
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		dotGit := filepath.Join(current, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			if info.IsDir() {
+				return Repo{Root: current, GitDir: dotGit, CommonDir: dotGit}, true
+			}
+			if repo, ok := readGitFile(current, dotGit); ok {
+				return repo, true
+			}
+		}
+
+		if parent := filepath.Dir(current); parent == current {
+			return Repo{}, false
+		}
+	}
+}
+
+// readGitFile resolves a .git file pointing at a linked worktree's git
+// directory. The worktree's commondir file points back at the shared one.
+func readGitFile(root, path string) (Repo, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Repo{}, false
+	}
+
+	gitDir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return Repo{}, false
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	gitDir = filepath.Clean(gitDir)
+
+	repo := Repo{Root: root, GitDir: gitDir, CommonDir: gitDir}
+
+	if commonData, readErr := os.ReadFile(filepath.Join(gitDir, "commondir")); readErr == nil {
+		commonDir := strings.TrimSpace(string(commonData))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		repo.CommonDir = filepath.Clean(commonDir)
+	}
+
+	if filepath.Base(filepath.Dir(gitDir)) == "worktrees" {
+		repo.Worktree = filepath.Base(gitDir)
+	}
+
+	return repo, true
+}
+
+// Name returns the repository name shared by all of its worktrees: the
+// directory holding the main working tree, or the bare repository's
+// directory without its .git suffix.
+func (r Repo) Name() string {
+	if filepath.Base(r.CommonDir) == ".git" {
+		return filepath.Base(filepath.Dir(r.CommonDir))
+	}
+	return strings.TrimSuffix(filepath.Base(r.CommonDir), ".git")
+}
+
+// Head reads the checked-out branch from HEAD. For a detached HEAD it
+// returns the abbreviated commit hash and detached = true.
+func (r Repo) Head() (branch string, detached bool, ok bool) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", false, false
+	}
+	return ParseHead(string(data))
+}
+
+// ParseHead parses the contents of a HEAD file.
+func ParseHead(content string) (branch string, detached bool, ok bool) {
+	content = strings.TrimSpace(content)
+	if ref, found := strings.CutPrefix(content, "ref:"); found {
+		ref = strings.TrimSpace(ref)
+		return strings.TrimPrefix(ref, "refs/heads/"), false, true
+	}
+	if len(content) >= 7 {
+		return content[:7], true, true
+	}
+	return "", false, false
+}
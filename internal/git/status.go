@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// statusTTL is how long a git status result is reused before rerunning.
+	statusTTL = 5 * time.Second
+
+	// statusTimeout bounds a single git status call on large repositories.
+	statusTimeout = 2 * time.Second
+)
+
+// Info is the git state of a session's working directory.
+type Info struct {
+	Root        string `json:"root"`               // Top-level directory of the working tree
+	Repository  string `json:"repository"`         // Repository name, the same in every worktree
+	CommonDir   string `json:"common_dir"`         // Git directory shared by all worktrees
+	Worktree    string `json:"worktree,omitempty"` // Linked worktree name; empty for the main tree
+	Branch      string `json:"branch"`             // Checked-out branch, or short hash when detached
+	Detached    bool   `json:"detached,omitempty"`
+	Dirty       int    `json:"dirty"`  // Changed, staged, conflicted and untracked files
+	Ahead       int    `json:"ahead"`  // Commits ahead of upstream
+	Behind      int    `json:"behind"` // Commits behind upstream
+	HasUpstream bool   `json:"has_upstream"`
+	HasStatus   bool   `json:"has_status"` // Whether git status succeeded (git may be missing)
+}
+
+// Status is the parsed output of git status --porcelain=v2 --branch.
+type Status struct {
+	Dirty       int
+	Ahead       int
+	Behind      int
+	HasUpstream bool
+}
+
+// statusEntry is a cached git status result.
+type statusEntry struct {
+	status  Status
+	ok      bool
+	fetched time.Time
+}
+
+var (
+	statusCacheMu sync.Mutex
+	statusCache   = make(map[string]statusEntry) // Keyed by working tree root
+)
+
+// Inspect returns the git state of dir. The repository and branch are read
+// from disk on every call; dirty and ahead/behind counts come from git
+// status, cached per working tree for a few seconds.
+func Inspect(dir string) (Info, bool) {
+	repo, found := FindRepo(dir)
+	if !found {
+		return Info{}, false
+	}
+
+	info := Info{Root: repo.Root, Repository: repo.Name(), CommonDir: repo.CommonDir, Worktree: repo.Worktree}
+	info.Branch, info.Detached, _ = repo.Head()
+
+	status, ok := cachedStatus(repo.Root, time.Now())
+	if ok {
+		info.Dirty = status.Dirty
+		info.Ahead = status.Ahead
+		info.Behind = status.Behind
+		info.HasUpstream = status.HasUpstream
+		info.HasStatus = true
+	}
+
+	return info, true
+}
+
+// cachedStatus returns the git status for root, running git at most once
+// per statusTTL.
+func cachedStatus(root string, now time.Time) (Status, bool) {
+	statusCacheMu.Lock()
+	entry, cached := statusCache[root]
+	statusCacheMu.Unlock()
+	if cached && now.Sub(entry.fetched) < statusTTL {
+		return entry.status, entry.ok
+	}
+
+	status, ok := runStatus(root)
+
+	statusCacheMu.Lock()
+	statusCache[root] = statusEntry{status: status, ok: ok, fetched: now}
+	statusCacheMu.Unlock()
+
+	return status, ok
+}
+
+// runStatus runs git status in root.
+func runStatus(root string) (Status, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "-C", root, "--no-optional-locks",
+		"status", "--porcelain=v2", "--branch", "--untracked-files=normal")
+	out, err := cmd.Output()
+	if err != nil {
+		return Status{}, false
+	}
+	return ParseStatus(string(out)), true
+}
+
+// ParseStatus parses git status --porcelain=v2 --branch output. Every
+// non-header line except ignored files counts as one dirty path.
+func ParseStatus(output string) Status {
+	var status Status
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.HasUpstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!"):
+			continue
+		default:
+			status.Dirty++
+		}
+	}
+	return status
+}
@@ -0,0 +1,199 @@
+// Package grouping splits the session table into collapsible groups by
+// project, repository, branch, source or state.
+package grouping
+
+import (
+	"fmt"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// By selects how the session table is grouped.
+type By int
+
+const (
+	None      By = iota // Flat list
+	ByProject           // One group per project
+	ByRepo              // One group per git repository, across worktrees
+	ByBranch            // One group per git branch
+	BySource            // One group per source (CLI, VSCode, ...)
+	ByState             // One group per state
+)
+
+// Count is the number of By values, for cycling through them.
+const Count = 6
+
+// names maps each By to its name in flags, views and the help line.
+var names = map[By]string{
+	None:      "none",
+	ByProject: "project",
+	ByRepo:    "repo",
+	ByBranch:  "branch",
+	BySource:  "source",
+	ByState:   "state",
+}
+
+// String returns the grouping's name, e.g. "repo".
+func (by By) String() string {
+	return names[by]
+}
+
+// Parse converts a grouping name to a By. An empty name means no grouping.
+func Parse(name string) (By, error) {
+	if name == "" {
+		return None, nil
+	}
+	for by, byName := range names {
+		if byName == name {
+			return by, nil
+		}
+	}
+	return None, fmt.Errorf("unknown grouping %q (want none, project, repo, branch, source or state)", name)
+}
+
+// Key returns the group a session belongs to under the given grouping.
+func Key(by By, s session.Session) string {
+	switch by {
+	case ByProject:
+		return s.Project
+	case ByRepo:
+		if s.Git == nil {
+			return s.Project
+		}
+		return s.Git.Repository
+	case ByBranch:
+		if s.CurrentBranch() == "" {
+			return "(no branch)"
+		}
+		return s.CurrentBranch()
+	case BySource:
+		return s.Source.Type
+	case ByState:
+		return s.State.String()
+	default:
+		return ""
+	}
+}
+
+// Group is the sessions sharing a group key.
+type Group struct {
+	Key      string
+	Sessions []session.Session
+}
+
+// Split splits sorted sessions into groups. Groups are ordered by their
+// first session, so the sort keys also order the groups.
+func Split(by By, sessions []session.Session) []*Group {
+	var groups []*Group
+	byKey := make(map[string]*Group)
+	for _, s := range sessions {
+		key := Key(by, s)
+		g, ok := byKey[key]
+		if !ok {
+			g = &Group{Key: key}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.Sessions = append(g.Sessions, s)
+	}
+	return groups
+}
+
+// Collapsed records which groups are collapsed. Keys are scoped to the
+// grouping, so collapsing the "api" project does not also collapse an "api"
+// branch.
+type Collapsed map[string]bool
+
+// Has reports whether the group with key is collapsed under by.
+func (c Collapsed) Has(by By, key string) bool {
+	return c[by.String()+":"+key]
+}
+
+// Set collapses or expands the group with key under by.
+func (c Collapsed) Set(by By, key string, collapsed bool) {
+	if collapsed {
+		c[by.String()+":"+key] = true
+	} else {
+		delete(c, by.String()+":"+key)
+	}
+}
+
+// Row is one line of the session table: a group header or a session.
+type Row struct {
+	Group   *Group // Set for group headers
+	Session session.Session
+}
+
+// IsHeader reports whether the row is a group header.
+func (r Row) IsHeader() bool {
+	return r.Group != nil
+}
+
+// Rows returns the rows of the session table: the sessions, interleaved
+// with group headers unless by is None. Sessions in collapsed groups are
+// omitted.
+func Rows(by By, sessions []session.Session, collapsed Collapsed) []Row {
+	if by == None {
+		rows := make([]Row, len(sessions))
+		for i, s := range sessions {
+			rows[i] = Row{Session: s}
+		}
+		return rows
+	}
+
+	var rows []Row
+	for _, g := range Split(by, sessions) {
+		rows = append(rows, Row{Group: g})
+		if collapsed.Has(by, g.Key) {
+			continue
+		}
+		for _, s := range g.Sessions {
+			rows = append(rows, Row{Session: s})
+		}
+	}
+	return rows
+}
+
+// SetCollapsed collapses or expands the group under the cursor and returns
+// the new cursor. On a session row it acts on the session's group, moving
+// the cursor to the group header when collapsing.
+func SetCollapsed(by By, rows []Row, cursor int, collapsed Collapsed, collapse bool) int {
+	if by == None || cursor < 0 || cursor >= len(rows) {
+		return cursor
+	}
+
+	headerIndex := cursor
+	for headerIndex > 0 && !rows[headerIndex].IsHeader() {
+		headerIndex--
+	}
+	if !rows[headerIndex].IsHeader() {
+		return cursor
+	}
+
+	collapsed.Set(by, rows[headerIndex].Group.Key, collapse)
+	if collapse {
+		return headerIndex
+	}
+	return cursor
+}
+
+// ToggleMarks marks every markable session in g, or unmarks them all when
+// they are already marked.
+func ToggleMarks(g *Group, marked map[int]bool, markable func(session.Session) bool) {
+	allMarked := true
+	for _, s := range g.Sessions {
+		if markable(s) && !marked[s.PID] {
+			allMarked = false
+		}
+	}
+	for _, s := range g.Sessions {
+		if !markable(s) {
+			continue
+		}
+		if allMarked {
+			delete(marked, s.PID)
+		} else {
+			marked[s.PID] = true
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// requestTimeout bounds one request to the hub.
+const requestTimeout = 10 * time.Second
+
+// Agent periodically pushes this machine's sessions to a hub.
+type Agent struct {
+	HubURL   string
+	Token    string
+	Name     string        // Hostname reported to the hub
+	Interval time.Duration // Time between pushes
+
+	// Discover returns the sessions to push.
+	Discover func() []session.Session
+
+	// Logf, when set, is told when pushes start failing and when they
+	// recover.
+	Logf func(format string, args ...any)
+}
+
+// Run pushes a snapshot every Interval until ctx is cancelled. Failed
+// pushes are retried on the next tick, so a restarted hub is picked up
+// without restarting the agent.
+func (a Agent) Run(ctx context.Context) error {
+	client := &http.Client{Timeout: requestTimeout}
+	var lastErr error
+	for {
+		sessions := a.Discover()
+		if sessions == nil {
+			sessions = []session.Session{}
+		}
+		err := Push(ctx, client, a.HubURL, a.Token, session.Snapshot{
+			GeneratedAt: time.Now(),
+			Hostname:    a.Name,
+			Sessions:    sessions,
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		switch {
+		case err != nil && (lastErr == nil || err.Error() != lastErr.Error()):
+			a.logf("push to %s failed: %v", a.HubURL, err)
+		case err == nil && lastErr != nil:
+			a.logf("pushing to %s again", a.HubURL)
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(a.Interval):
+		}
+	}
+}
+
+// logf reports through Logf when it is set.
+func (a Agent) logf(format string, args ...any) {
+	if a.Logf != nil {
+		a.Logf(format, args...)
+	}
+}
+
+// Push sends one snapshot to the hub.
+func Push(ctx context.Context, client *http.Client, hubURL, token string, snapshot session.Snapshot) error {
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint(hubURL, SnapshotsPath), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// checkResponse turns a non-2xx response into an error carrying the hub's
+// message.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if text := strings.TrimSpace(string(message)); text != "" {
+		return fmt.Errorf("hub: %s (%s)", text, resp.Status)
+	}
+	return fmt.Errorf("hub: %s", resp.Status)
+}
@@ -0,0 +1,208 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/remote"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const (
+	// pollInterval is how often a client fetches the merged view.
+	pollInterval = 1 * time.Second
+
+	// maxBackoff bounds the delay between polls while the hub is
+	// unreachable; the delay doubles on each failure.
+	maxBackoff = 30 * time.Second
+)
+
+// Client polls a hub in the background and reports its agents in the same
+// form as remote.Pool reports ssh hosts.
+type Client struct {
+	url   string
+	token string
+	self  string // Agent whose sessions are already shown locally
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	view     View
+	polled   bool          // A poll has completed, successfully or not
+	polledAt time.Time     // When the latest successful poll finished
+	latency  time.Duration // Round trip of the latest successful poll
+	err      error         // Why the latest poll failed; nil after a success
+	retryAt  time.Time
+}
+
+// Connect starts polling the hub at hubURL. Sessions pushed by the agent
+// named self are skipped, since they are this machine's own.
+func Connect(hubURL, token, self string) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{url: hubURL, token: token, self: self, cancel: cancel}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx)
+	}()
+	return c
+}
+
+// Close stops polling.
+func (c *Client) Close() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// run polls until ctx is cancelled, backing off while the hub is down.
+func (c *Client) run(ctx context.Context) {
+	client := &http.Client{Timeout: requestTimeout}
+	delay := pollInterval
+	for {
+		start := time.Now()
+		view, err := Fetch(ctx, client, c.url, c.token)
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.mu.Lock()
+		c.polled = true
+		if err != nil {
+			// Drop the method and URL that url.Error repeats
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			c.err = err
+			c.retryAt = time.Now().Add(delay)
+			c.view = View{}
+		} else {
+			c.err = nil
+			c.view = view
+			c.polledAt = time.Now()
+			c.latency = c.polledAt.Sub(start)
+			delay = pollInterval
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if err != nil {
+			delay = min(delay*2, maxBackoff)
+		}
+	}
+}
+
+// Sessions returns the sessions in the latest merged view, each tagged with
+// the agent that reported it.
+func (c *Client) Sessions() []session.Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var sessions []session.Session
+	for _, s := range c.view.Sessions {
+		if s.Host != c.self {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// Status returns one entry per agent known to the hub, or a single entry for
+// the hub itself while it is unreachable or has no agents.
+func (c *Client) Status(now time.Time) []remote.HostStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hub := remote.HostStatus{Target: c.url, Label: "hub " + hubLabel(c.url)}
+	switch {
+	case !c.polled:
+		hub.State = remote.HostConnecting
+		return []remote.HostStatus{hub}
+	case c.err != nil:
+		hub.State = remote.HostDisconnected
+		hub.Err = c.err.Error()
+		hub.RetryAt = c.retryAt
+		return []remote.HostStatus{hub}
+	}
+
+	var statuses []remote.HostStatus
+	for _, agent := range c.view.Agents {
+		if agent.Name == c.self {
+			continue
+		}
+		status := remote.HostStatus{
+			Target:   agent.Name,
+			Label:    agent.Name,
+			State:    remote.HostConnected,
+			Latency:  c.latency,
+			LastSeen: c.polledAt.Add(-agent.Age),
+			Sessions: agent.Sessions,
+		}
+		if agent.Stale || now.Sub(status.LastSeen) > staleAfter {
+			status.State = remote.HostStale
+		}
+		statuses = append(statuses, status)
+	}
+	if len(statuses) == 0 {
+		hub.State = remote.HostConnected
+		hub.Latency = c.latency
+		hub.LastSeen = c.polledAt
+		statuses = append(statuses, hub)
+	}
+	return statuses
+}
+
+// WaitReady blocks until the first poll completes or until timeout.
+func (c *Client) WaitReady(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		polled := c.polled
+		c.mu.Unlock()
+		if polled {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Fetch retrieves the merged view from the hub.
+func Fetch(ctx context.Context, client *http.Client, hubURL, token string) (View, error) {
+	var view View
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint(hubURL, SessionsPath), nil)
+	if err != nil {
+		return view, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return view, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return view, err
+	}
+	err = json.NewDecoder(resp.Body).Decode(&view)
+	return view, err
+}
+
+// hubLabel returns the host:port of a hub URL for display.
+func hubLabel(hubURL string) string {
+	parsed, err := url.Parse(endpoint(hubURL, ""))
+	if err != nil || parsed.Host == "" {
+		return hubURL
+	}
+	return parsed.Host
+}
@@ -0,0 +1,194 @@
+// Package hub aggregates session snapshots pushed over HTTP by cctop agents
+// on several machines and serves the merged view to cctop clients.
+package hub
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const (
+	// SnapshotsPath receives snapshots POSTed by agents.
+	SnapshotsPath = "/v1/snapshots"
+
+	// SessionsPath serves the merged view to clients.
+	SessionsPath = "/v1/sessions"
+
+	// staleAfter is how long an agent may go without pushing before it is
+	// reported as stale.
+	staleAfter = 10 * time.Second
+
+	// expireAfter is how long an agent may go without pushing before its
+	// sessions are dropped.
+	expireAfter = time.Minute
+
+	// maxSnapshotBytes caps one snapshot pushed by an agent.
+	maxSnapshotBytes = 16 * 1024 * 1024
+)
+
+// AgentStatus describes one agent in the merged view.
+type AgentStatus struct {
+	Name     string        `json:"name"`
+	Age      time.Duration `json:"age"` // Time since the agent last pushed, in nanoseconds
+	Stale    bool          `json:"stale"`
+	Sessions int           `json:"sessions"`
+}
+
+// View is the merged view served to clients. Sessions are tagged with the
+// name of the agent that reported them.
+type View struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Agents      []AgentStatus     `json:"agents"`
+	Sessions    []session.Session `json:"sessions"`
+}
+
+// report is the latest snapshot from one agent.
+type report struct {
+	snapshot   session.Snapshot
+	receivedAt time.Time
+}
+
+// Server is the hub's HTTP handler. Every request must carry the shared
+// token as a bearer token.
+type Server struct {
+	token string
+
+	mu      sync.Mutex
+	reports map[string]report // Keyed by agent name
+}
+
+// NewServer creates a hub that accepts requests bearing token.
+func NewServer(token string) *Server {
+	return &Server{token: token, reports: make(map[string]report)}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !authorized(r, s.token) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case SnapshotsPath:
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.receive(w, r)
+	case SessionsPath:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.View(time.Now()))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// receive stores a pushed snapshot under its hostname.
+func (s *Server) receive(w http.ResponseWriter, r *http.Request) {
+	var snapshot session.Snapshot
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSnapshotBytes)).Decode(&snapshot); err != nil {
+		http.Error(w, "invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if snapshot.Hostname == "" {
+		http.Error(w, "snapshot has no hostname", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.reports[snapshot.Hostname] = report{snapshot: snapshot, receivedAt: time.Now()}
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// View merges the latest snapshot from every agent, forgetting agents that
+// have not pushed for expireAfter. Agents are ordered by name.
+func (s *Server) View(now time.Time) View {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	view := View{GeneratedAt: now, Agents: []AgentStatus{}, Sessions: []session.Session{}}
+	names := make([]string, 0, len(s.reports))
+	for name, report := range s.reports {
+		if now.Sub(report.receivedAt) > expireAfter {
+			delete(s.reports, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		report := s.reports[name]
+		age := now.Sub(report.receivedAt)
+		view.Agents = append(view.Agents, AgentStatus{
+			Name:     name,
+			Age:      age,
+			Stale:    age > staleAfter,
+			Sessions: len(report.snapshot.Sessions),
+		})
+		for _, sess := range report.snapshot.Sessions {
+			sess.Host = name
+			view.Sessions = append(view.Sessions, sess)
+		}
+	}
+	return view
+}
+
+// authorized reports whether r carries token as its bearer token.
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// InsecureURL reports whether requests to hubURL would carry the token and
+// sessions unencrypted across the network: plain HTTP to a host that is not
+// a loopback address.
+func InsecureURL(hubURL string) bool {
+	parsed, err := url.Parse(endpoint(hubURL, ""))
+	if err != nil || parsed.Scheme != "http" {
+		return false
+	}
+	return !loopbackHost(parsed.Hostname())
+}
+
+// loopbackHost reports whether host names this machine: localhost or a
+// loopback IP. An empty host, as in a ":7433" listen address, means every
+// interface and is not loopback.
+func loopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// LoopbackAddr reports whether a listen address (host:port) accepts only
+// connections from this machine.
+func LoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && loopbackHost(host)
+}
+
+// endpoint joins a hub URL and a path, defaulting to http:// when the URL
+// has no scheme.
+func endpoint(hubURL, path string) string {
+	if !strings.Contains(hubURL, "://") {
+		hubURL = "http://" + hubURL
+	}
+	return strings.TrimSuffix(hubURL, "/") + path
+}
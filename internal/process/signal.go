@@ -0,0 +1,59 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// SignalResult classifies the outcome of sending a signal to a process.
+type SignalResult int
+
+const (
+	SignalSent             SignalResult = iota // Signal delivered
+	SignalPermissionDenied                     // Process owned by another user
+	SignalAlreadyExited                        // Process no longer exists
+	SignalFailed                               // Any other error
+)
+
+// String returns the human-readable description of a SignalResult.
+func (r SignalResult) String() string {
+	switch r {
+	case SignalSent:
+		return "sent"
+	case SignalPermissionDenied:
+		return "permission denied"
+	case SignalAlreadyExited:
+		return "already exited"
+	default:
+		return "failed"
+	}
+}
+
+// Signal sends sig to pid and classifies the result.
+func Signal(pid int, sig syscall.Signal) SignalResult {
+	if pid <= 0 {
+		return SignalAlreadyExited
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return SignalAlreadyExited
+	}
+
+	return ClassifySignalError(proc.Signal(sig))
+}
+
+// ClassifySignalError maps an error from os.Process.Signal to a SignalResult.
+func ClassifySignalError(err error) SignalResult {
+	switch {
+	case err == nil:
+		return SignalSent
+	case errors.Is(err, os.ErrProcessDone), errors.Is(err, syscall.ESRCH):
+		return SignalAlreadyExited
+	case errors.Is(err, os.ErrPermission), errors.Is(err, syscall.EPERM):
+		return SignalPermissionDenied
+	default:
+		return SignalFailed
+	}
+}
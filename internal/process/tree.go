@@ -0,0 +1,333 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicks is USER_HZ, the unit of CPU and start times in /proc/<pid>/stat.
+// It is 100 on every mainstream Linux platform and cannot be read without
+// cgo.
+const clockTicks = 100
+
+// Process is one process's identity and resource usage.
+type Process struct {
+	PID        int
+	PPID       int
+	Name       string        // Executable name (comm), e.g. "node"
+	Command    string        // Full command line; empty when unreadable
+	CPUTime    time.Duration // User plus system CPU time consumed so far
+	CPUPercent float64       // CPU use since the previous sample; 100 is one core
+	RSS        int64         // Resident memory in bytes
+	Runtime    time.Duration // Time since the process started
+}
+
+// Label returns the command line, or the executable name when the command
+// line is unreadable (kernel threads, zombies, other users on macOS).
+func (p Process) Label() string {
+	if p.Command != "" {
+		return p.Command
+	}
+	return p.Name
+}
+
+// Table is a snapshot of the process list, indexed by PID and parent.
+type Table struct {
+	procs    map[int]Process
+	children map[int][]int
+}
+
+// NewTable indexes procs by PID and parent PID. Children are ordered by PID.
+func NewTable(procs []Process) Table {
+	t := Table{procs: make(map[int]Process, len(procs)), children: make(map[int][]int)}
+	for _, p := range procs {
+		t.procs[p.PID] = p
+		if p.PPID != p.PID {
+			t.children[p.PPID] = append(t.children[p.PPID], p.PID)
+		}
+	}
+	for _, pids := range t.children {
+		sort.Ints(pids)
+	}
+	return t
+}
+
+// Len returns the number of processes in the table.
+func (t Table) Len() int {
+	return len(t.procs)
+}
+
+// Get returns the process with the given PID.
+func (t Table) Get(pid int) (Process, bool) {
+	p, ok := t.procs[pid]
+	return p, ok
+}
+
+// Children returns the direct children of pid, ordered by PID.
+func (t Table) Children(pid int) []Process {
+	var children []Process
+	for _, child := range t.children[pid] {
+		children = append(children, t.procs[child])
+	}
+	return children
+}
+
+// Descendants returns every process below pid, depth-first.
+func (t Table) Descendants(pid int) []Process {
+	var descendants []Process
+	seen := map[int]bool{pid: true}
+	var walk func(pid int)
+	walk = func(pid int) {
+		for _, child := range t.Children(pid) {
+			// PID reuse can, in a racy snapshot, make a cycle
+			if seen[child.PID] {
+				continue
+			}
+			seen[child.PID] = true
+			descendants = append(descendants, child)
+			walk(child.PID)
+		}
+	}
+	walk(pid)
+	return descendants
+}
+
+// ErrUnsupported is returned by ReadProcesses on platforms without /proc or
+// a BSD ps.
+var ErrUnsupported = errors.New("process listing is not supported on " + runtime.GOOS)
+
+// ReadProcesses lists all processes: from /proc on Linux, from one ps call on
+// macOS. CPUPercent is filled only where the platform reports it (macOS);
+// Sampler computes it elsewhere.
+func ReadProcesses() ([]Process, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return readProcFS("/proc")
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		out, err := exec.Command("ps", "-axo", "pid=,ppid=,%cpu=,rss=,etime=,time=,command=").Output()
+		if err != nil {
+			return nil, err
+		}
+		return ParsePSProcesses(string(out)), nil
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// readProcFS reads every process under root (normally /proc).
+func readProcFS(root string) ([]Process, error) {
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	uptime := readUptime(filepath.Join(root, "uptime"))
+	pageSize := int64(os.Getpagesize())
+
+	var procs []Process
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil {
+			continue
+		}
+		// Processes exit between listing and reading; skip them
+		stat, err := os.ReadFile(filepath.Join(root, dir.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		p, err := ParseStat(string(stat), uptime)
+		if err != nil || p.PID != pid {
+			continue
+		}
+		if statm, err := os.ReadFile(filepath.Join(root, dir.Name(), "statm")); err == nil {
+			p.RSS = ParseStatm(string(statm), pageSize)
+		}
+		if cmdline, err := os.ReadFile(filepath.Join(root, dir.Name(), "cmdline")); err == nil {
+			p.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// readUptime returns the system uptime from /proc/uptime, or 0.
+func readUptime(path string) time.Duration {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ParseStat parses a /proc/<pid>/stat line. uptime is the system uptime,
+// used to turn the start time into a runtime; with 0 the runtime is left
+// unset. RSS comes from statm instead (see ParseStatm).
+func ParseStat(stat string, uptime time.Duration) (Process, error) {
+	// comm is parenthesised and may itself contain spaces and parentheses
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return Process{}, fmt.Errorf("malformed stat: %q", stat)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(stat[:open]))
+	if err != nil {
+		return Process{}, fmt.Errorf("malformed stat pid: %w", err)
+	}
+
+	// Fields from the state (field 3) onwards; field n is at index n-3
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 20 {
+		return Process{}, fmt.Errorf("stat has %d fields after comm, want at least 20", len(fields))
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	startTicks, _ := strconv.ParseInt(fields[19], 10, 64)
+
+	p := Process{
+		PID:     pid,
+		PPID:    ppid,
+		Name:    stat[open+1 : closing],
+		CPUTime: ticksToDuration(utime + stime),
+	}
+	if uptime > 0 {
+		p.Runtime = max(uptime-ticksToDuration(startTicks), 0)
+	}
+	return p, nil
+}
+
+// ParseStatm returns the resident set size in bytes from a
+// /proc/<pid>/statm line (size resident shared ...), counted in pages.
+func ParseStatm(statm string, pageSize int64) int64 {
+	fields := strings.Fields(statm)
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * pageSize
+}
+
+// ticksToDuration converts clock ticks to a duration.
+func ticksToDuration(ticks int64) time.Duration {
+	return time.Duration(ticks) * time.Second / clockTicks
+}
+
+// ParsePSProcesses parses `ps -axo pid=,ppid=,%cpu=,rss=,etime=,time=,command=`
+// output. rss is in KiB; etime and time are [[dd-]hh:]mm:ss[.cc].
+func ParsePSProcesses(output string) []Process {
+	var procs []Process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+		pid, pidErr := strconv.Atoi(fields[0])
+		ppid, ppidErr := strconv.Atoi(fields[1])
+		if pidErr != nil || ppidErr != nil {
+			continue
+		}
+		cpu, _ := strconv.ParseFloat(fields[2], 64)
+		rssKiB, _ := strconv.ParseInt(fields[3], 10, 64)
+		command := strings.Join(fields[6:], " ")
+		procs = append(procs, Process{
+			PID:        pid,
+			PPID:       ppid,
+			Name:       filepath.Base(fields[6]),
+			Command:    command,
+			CPUTime:    parseClock(fields[5]),
+			CPUPercent: cpu,
+			RSS:        rssKiB * 1024,
+			Runtime:    parseClock(fields[4]),
+		})
+	}
+	return procs
+}
+
+// parseClock parses ps's [[dd-]hh:]mm:ss[.cc] durations.
+func parseClock(text string) time.Duration {
+	var days int64
+	if before, after, found := strings.Cut(text, "-"); found {
+		days, _ = strconv.ParseInt(before, 10, 64)
+		text = after
+	}
+	var total float64
+	for _, part := range strings.Split(text, ":") {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + value
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(total*float64(time.Second))
+}
+
+// Sampler reads process tables and fills in CPUPercent from the CPU time
+// each process used since the previous sample. It is safe for concurrent
+// use.
+type Sampler struct {
+	mu       sync.Mutex
+	previous map[int]time.Duration // CPU time per PID at the previous sample
+	at       time.Time             // When the previous sample was taken
+	read     func() ([]Process, error)
+}
+
+// NewSampler returns a Sampler over the system's processes.
+func NewSampler() *Sampler {
+	return &Sampler{read: ReadProcesses}
+}
+
+// NewSamplerFunc returns a Sampler over the processes read returns, for
+// tests and other sources.
+func NewSamplerFunc(read func() ([]Process, error)) *Sampler {
+	return &Sampler{read: read}
+}
+
+// Sample reads the process list. A process first seen in this sample is
+// given its lifetime average, so the first sample is already useful;
+// platforms that report CPU% themselves keep their value.
+func (s *Sampler) Sample(now time.Time) (Table, error) {
+	procs, err := s.read()
+	if err != nil {
+		return Table{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := now.Sub(s.at)
+	current := make(map[int]time.Duration, len(procs))
+	for i := range procs {
+		p := &procs[i]
+		current[p.PID] = p.CPUTime
+		if p.CPUPercent > 0 {
+			continue
+		}
+		if before, seen := s.previous[p.PID]; seen && elapsed > 0 && p.CPUTime >= before {
+			p.CPUPercent = 100 * float64(p.CPUTime-before) / float64(elapsed)
+		} else if p.Runtime > 0 {
+			p.CPUPercent = 100 * float64(p.CPUTime) / float64(p.Runtime)
+		}
+	}
+	s.previous, s.at = current, now
+
+	return NewTable(procs), nil
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies a lexical token.
+type tokenKind int
+
+const (
+	tokenWord   tokenKind = iota // A term such as state:waiting or a bare word
+	tokenAnd                     // AND
+	tokenOr                      // OR
+	tokenNot                     // NOT or a leading -
+	tokenLParen                  // (
+	tokenRParen                  // )
+	tokenEOF
+)
+
+// token is a lexical token and its byte position in the input.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// ParseError describes a syntax or value error at a byte position.
+type ParseError struct {
+	Pos     int
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Message)
+}
+
+// lex splits a query string into tokens. Words may contain double-quoted
+// sections (e.g. topic:"fix login") which are unquoted in the token text.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+
+	for i < len(input) {
+		r := rune(input[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '-' && (i+1 < len(input) && !unicode.IsSpace(rune(input[i+1]))):
+			tokens = append(tokens, token{kind: tokenNot, text: "-", pos: i})
+			i++
+		default:
+			start := i
+			var word strings.Builder
+			for i < len(input) {
+				c := input[i]
+				if c == '"' {
+					end := strings.IndexByte(input[i+1:], '"')
+					if end == -1 {
+						return nil, &ParseError{Pos: i, Message: "unterminated quote"}
+					}
+					word.WriteString(input[i+1 : i+1+end])
+					i += end + 2
+					continue
+				}
+				if unicode.IsSpace(rune(c)) || c == '(' || c == ')' {
+					break
+				}
+				word.WriteByte(c)
+				i++
+			}
+
+			text := word.String()
+			switch text {
+			case "AND", "&&":
+				tokens = append(tokens, token{kind: tokenAnd, text: text, pos: start})
+			case "OR", "||":
+				tokens = append(tokens, token{kind: tokenOr, text: text, pos: start})
+			case "NOT", "!":
+				tokens = append(tokens, token{kind: tokenNot, text: text, pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenWord, text: text, pos: start})
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(input)})
+	return tokens, nil
+}
@@ -0,0 +1,205 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// Query is a parsed filter expression. The zero value (and a query parsed
+// from an empty string) matches every session.
+type Query struct {
+	root node
+}
+
+// node is an expression tree node.
+type node interface {
+	match(s session.Session, now time.Time) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ operand node }
+
+func (n andNode) match(s session.Session, now time.Time) bool {
+	return n.left.match(s, now) && n.right.match(s, now)
+}
+
+func (n orNode) match(s session.Session, now time.Time) bool {
+	return n.left.match(s, now) || n.right.match(s, now)
+}
+
+func (n notNode) match(s session.Session, now time.Time) bool {
+	return !n.operand.match(s, now)
+}
+
+// Parse parses a filter expression such as
+//
+//	state:waiting src:cli project:api* branch:feat/ age>10m tokens>100k -topic:refactor
+//
+// Adjacent terms are ANDed; OR, NOT (or a leading -), AND and parentheses
+// combine them. Precedence from highest to lowest: NOT, AND, OR.
+func Parse(input string) (*Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return &Query{}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &ParseError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return &Query{root: root}, nil
+}
+
+// Match reports whether the session satisfies the query.
+func (q *Query) Match(s session.Session) bool {
+	return q.MatchAt(s, time.Now())
+}
+
+// MatchAt reports whether the session satisfies the query, evaluating
+// time-relative fields (such as idle) against now.
+func (q *Query) MatchAt(s session.Session, now time.Time) bool {
+	if q == nil || q.root == nil {
+		return true
+	}
+	return q.root.match(s, now)
+}
+
+// IsEmpty reports whether the query matches everything.
+func (q *Query) IsEmpty() bool {
+	return q == nil || q.root == nil
+}
+
+// parser is a recursive-descent parser over lexed tokens.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, rightErr := p.parseAnd()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenWord, tokenNot, tokenLParen:
+			// Implicit AND between adjacent terms
+		default:
+			return left, nil
+		}
+
+		right, rightErr := p.parseUnary()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		left = andNode{left: left, right: right}
+	}
+}
+
+// parseUnary parses: ("NOT" | "-") unary | "(" or ")" | term
+func (p *parser) parseUnary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNot:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &ParseError{Pos: closing.pos, Message: "missing )"}
+		}
+		return inner, nil
+	case tokenWord:
+		return parseTerm(tok)
+	case tokenEOF:
+		return nil, &ParseError{Pos: tok.pos, Message: "expected a term"}
+	default:
+		return nil, &ParseError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+}
+
+// parseNumber parses an integer with an optional k/m suffix (e.g. 100k, 1.5M).
+func parseNumber(text string) (float64, error) {
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(text, "k"), strings.HasSuffix(text, "K"):
+		multiplier = 1_000
+		text = text[:len(text)-1]
+	case strings.HasSuffix(text, "m"), strings.HasSuffix(text, "M"):
+		multiplier = 1_000_000
+		text = text[:len(text)-1]
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, err
+	}
+	return value * multiplier, nil
+}
+
+// parseDurationValue parses a Go duration, also accepting whole days (e.g. 2d).
+func parseDurationValue(text string) (time.Duration, error) {
+	if strings.HasSuffix(text, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(text, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(text)
+}
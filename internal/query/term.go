@@ -0,0 +1,240 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/git"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// fieldKind determines how a field's values are parsed and compared.
+type fieldKind int
+
+const (
+	kindText     fieldKind = iota // Case-insensitive substring or glob match
+	kindNumber                    // Integer with optional k/m suffix
+	kindDuration                  // Go duration with optional d suffix
+)
+
+// field describes one filterable session attribute.
+type field struct {
+	kind     fieldKind
+	text     func(s session.Session) string
+	number   func(s session.Session) float64
+	duration func(s session.Session, now time.Time) time.Duration
+}
+
+// fields maps field names (and aliases) to their definitions.
+var fields = map[string]field{
+	"state":   {kind: kindText, text: func(s session.Session) string { return s.State.String() }},
+	"src":     {kind: kindText, text: func(s session.Session) string { return s.Source.Type }},
+	"source":  {kind: kindText, text: func(s session.Session) string { return s.Source.Type }},
+	"project": {kind: kindText, text: func(s session.Session) string { return s.Project }},
+	"topic":   {kind: kindText, text: func(s session.Session) string { return s.Topic }},
+	"branch":  {kind: kindText, text: func(s session.Session) string { return s.CurrentBranch() }},
+	"cwd":     {kind: kindText, text: func(s session.Session) string { return s.CWD }},
+	"id":      {kind: kindText, text: func(s session.Session) string { return s.SessionID }},
+	"tty":     {kind: kindText, text: func(s session.Session) string { return s.TTY }},
+	"host":    {kind: kindText, text: func(s session.Session) string { return s.Host }},
+	"user":    {kind: kindText, text: func(s session.Session) string { return s.User }},
+	"model":   {kind: kindText, text: func(s session.Session) string { return s.Model }},
+	"mode":    {kind: kindText, text: func(s session.Session) string { return s.PermissionMode }},
+	"repo": {kind: kindText, text: func(s session.Session) string {
+		if s.Git == nil {
+			return ""
+		}
+		return s.Git.Repository
+	}},
+	"worktree": {kind: kindText, text: func(s session.Session) string {
+		if s.Git == nil {
+			return ""
+		}
+		return s.Git.Worktree
+	}},
+
+	"pid":         {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.PID) }},
+	"msgs":        {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Messages) }},
+	"messages":    {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Messages) }},
+	"tokens":      {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Usage.Total()) }},
+	"files":       {kind: kindNumber, number: func(s session.Session) float64 { return float64(len(s.Files)) }},
+	"conflicts":   {kind: kindNumber, number: func(s session.Session) float64 { return float64(len(s.Conflicts)) }},
+	"ctx":         {kind: kindNumber, number: func(s session.Session) float64 { return float64(max(s.ContextPercent(), 0)) }},
+	"compactions": {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Compactions) }},
+	"cpu":         {kind: kindNumber, number: func(s session.Session) float64 { return s.CPUPercent }},
+	"mem":         {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Memory) }},
+	"memory":      {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Memory) }},
+	"procs":       {kind: kindNumber, number: func(s session.Session) float64 { return float64(s.Processes) }},
+	"dirty":       {kind: kindNumber, number: gitNumber(func(info *git.Info) int { return info.Dirty })},
+	"ahead":       {kind: kindNumber, number: gitNumber(func(info *git.Info) int { return info.Ahead })},
+	"behind":      {kind: kindNumber, number: gitNumber(func(info *git.Info) int { return info.Behind })},
+
+	"age": {kind: kindDuration, duration: func(s session.Session, _ time.Time) time.Duration { return s.Duration }},
+	"dur": {kind: kindDuration, duration: func(s session.Session, _ time.Time) time.Duration { return s.Duration }},
+	"idle": {kind: kindDuration, duration: func(s session.Session, now time.Time) time.Duration {
+		if s.LastActivity.IsZero() {
+			return 0
+		}
+		return now.Sub(s.LastActivity)
+	}},
+}
+
+// gitNumber adapts a git counter to a number field; sessions outside a
+// repository count as zero.
+func gitNumber(get func(info *git.Info) int) func(s session.Session) float64 {
+	return func(s session.Session) float64 {
+		if s.Git == nil {
+			return 0
+		}
+		return float64(get(s.Git))
+	}
+}
+
+// operators lists comparison operators, longest first so >= wins over >.
+var operators = []string{">=", "<=", ":", "=", ">", "<"}
+
+// textNode matches a text field (or, for bare words, any of project, topic
+// and branch) against a lowercase pattern. Patterns containing * are
+// anchored globs; ":" is a substring match and "=" an exact match.
+type textNode struct {
+	field   *field
+	pattern string
+	exact   bool
+}
+
+func (n textNode) match(s session.Session, _ time.Time) bool {
+	if n.field == nil {
+		for _, value := range []string{s.Project, s.Topic, s.CurrentBranch()} {
+			if matchText(value, n.pattern, false) {
+				return true
+			}
+		}
+		return false
+	}
+	return matchText(n.field.text(s), n.pattern, n.exact)
+}
+
+// compareNode compares a numeric or duration field against a value.
+type compareNode struct {
+	field *field
+	op    string
+	value float64
+}
+
+func (n compareNode) match(s session.Session, now time.Time) bool {
+	var actual float64
+	if n.field.kind == kindDuration {
+		actual = float64(n.field.duration(s, now))
+	} else {
+		actual = n.field.number(s)
+	}
+
+	switch n.op {
+	case ">":
+		return actual > n.value
+	case ">=":
+		return actual >= n.value
+	case "<":
+		return actual < n.value
+	case "<=":
+		return actual <= n.value
+	default:
+		return actual == n.value
+	}
+}
+
+// parseTerm turns a word token into a field comparison or a bare text match.
+func parseTerm(tok token) (node, error) {
+	name, op, value, found := splitTerm(tok.text)
+	if !found {
+		return textNode{pattern: strings.ToLower(tok.text)}, nil
+	}
+
+	def, known := fields[strings.ToLower(name)]
+	if !known {
+		return nil, &ParseError{Pos: tok.pos, Message: fmt.Sprintf("unknown field %q", name)}
+	}
+	valuePos := tok.pos + len(name) + len(op)
+	if value == "" {
+		return nil, &ParseError{Pos: valuePos, Message: fmt.Sprintf("missing value for %s", name)}
+	}
+
+	switch def.kind {
+	case kindText:
+		if op != ":" && op != "=" {
+			return nil, &ParseError{Pos: tok.pos + len(name), Message: fmt.Sprintf("%s only supports : and =", name)}
+		}
+		return textNode{field: &def, pattern: strings.ToLower(value), exact: op == "="}, nil
+	case kindDuration:
+		duration, err := parseDurationValue(value)
+		if err != nil {
+			return nil, &ParseError{Pos: valuePos, Message: fmt.Sprintf("invalid duration %q", value)}
+		}
+		return compareNode{field: &def, op: op, value: float64(duration)}, nil
+	default:
+		number, err := parseNumber(value)
+		if err != nil {
+			return nil, &ParseError{Pos: valuePos, Message: fmt.Sprintf("invalid number %q", value)}
+		}
+		return compareNode{field: &def, op: op, value: number}, nil
+	}
+}
+
+// splitTerm splits "name<op>value" at the first operator. A word whose
+// prefix before the operator is not an identifier is treated as bare text.
+func splitTerm(text string) (name, op, value string, found bool) {
+	index := strings.IndexAny(text, ":=<>")
+	if index <= 0 {
+		return "", "", "", false
+	}
+
+	name = text[:index]
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return "", "", "", false
+		}
+	}
+
+	rest := text[index:]
+	for _, candidate := range operators {
+		if strings.HasPrefix(rest, candidate) {
+			return name, candidate, rest[len(candidate):], true
+		}
+	}
+	return "", "", "", false
+}
+
+// matchText reports whether value matches a lowercase pattern.
+func matchText(value, pattern string, exact bool) bool {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(pattern, "*"):
+		return matchGlob(value, pattern)
+	case exact:
+		return value == pattern
+	default:
+		return strings.Contains(value, pattern)
+	}
+}
+
+// matchGlob matches value against a pattern where * matches any run of
+// characters, including path separators. The match is anchored at both ends.
+func matchGlob(value, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(value, part)
+		if index == -1 {
+			return false
+		}
+		value = value[index+len(part):]
+	}
+	return strings.HasSuffix(value, last)
+}
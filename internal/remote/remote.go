@@ -0,0 +1,301 @@
+// Package remote streams session snapshots from cctop agents running on
+// other machines, started over an SSH command channel.
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const (
+	// minBackoff and maxBackoff bound the delay before reconnecting to a
+	// host whose agent exited; the delay doubles on each failure.
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+
+	// staleAfter is how long a connected host may go without a snapshot
+	// before it is reported as stale.
+	staleAfter = 10 * time.Second
+
+	// waitDelay bounds how long a killed agent's I/O may linger.
+	waitDelay = 2 * time.Second
+
+	// maxSnapshotBytes caps one snapshot line from an agent.
+	maxSnapshotBytes = 16 * 1024 * 1024
+)
+
+// Dialer builds the command that runs a streaming JSON agent for a target.
+type Dialer func(ctx context.Context, target string) *exec.Cmd
+
+// SSHDialer runs remoteCommand --format json on the target over ssh. Batch
+// mode makes missing keys fail fast instead of prompting, and keepalives
+// detect dropped connections. The target follows "--" so one starting with
+// a dash cannot pass options to ssh.
+func SSHDialer(remoteCommand string) Dialer {
+	return func(ctx context.Context, target string) *exec.Cmd {
+		return exec.CommandContext(ctx, "ssh",
+			"-T",
+			"-o", "BatchMode=yes",
+			"-o", "ConnectTimeout=10",
+			"-o", "ServerAliveInterval=5",
+			"-o", "ServerAliveCountMax=3",
+			"--",
+			target,
+			remoteCommand+" --format json")
+	}
+}
+
+// HostState is the connection state of one remote host.
+type HostState int
+
+const (
+	HostConnecting   HostState = iota // Agent started, no snapshot yet
+	HostConnected                     // Receiving snapshots
+	HostStale                         // Connected but no recent snapshot
+	HostDisconnected                  // Agent exited; waiting to reconnect
+)
+
+// String returns the display name of the state.
+func (s HostState) String() string {
+	switch s {
+	case HostConnecting:
+		return "connecting"
+	case HostConnected:
+		return "connected"
+	case HostStale:
+		return "stale"
+	default:
+		return "disconnected"
+	}
+}
+
+// HostStatus describes one remote host for display.
+type HostStatus struct {
+	Target   string
+	Label    string // Host name shown in the HOST column and header
+	State    HostState
+	Latency  time.Duration // Time from starting the agent to its first snapshot
+	LastSeen time.Time     // When the latest snapshot arrived
+	Err      string        // Why the agent last exited
+	RetryAt  time.Time     // When the next reconnect attempt starts
+	Sessions int
+	Failures int // Consecutive connections that ended without a snapshot
+}
+
+// host tracks the agent connection to one target.
+type host struct {
+	target string
+
+	mu        sync.Mutex
+	status    HostStatus
+	sessions  []session.Session
+	startedAt time.Time
+}
+
+// Pool maintains agent connections to a set of hosts, reconnecting with
+// backoff when an agent exits.
+type Pool struct {
+	hosts  []*host
+	dial   Dialer
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Start connects to every target in the background.
+func Start(targets []string, dial Dialer) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &Pool{dial: dial, cancel: cancel}
+
+	for _, target := range targets {
+		h := &host{
+			target: target,
+			status: HostStatus{Target: target, Label: HostLabel(target)},
+		}
+		pool.hosts = append(pool.hosts, h)
+
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			pool.run(ctx, h)
+		}()
+	}
+	return pool
+}
+
+// Close stops all agents and waits for their goroutines to exit.
+func (p *Pool) Close() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Sessions returns the latest sessions from every connected host, each
+// tagged with its host's target.
+func (p *Pool) Sessions() []session.Session {
+	var sessions []session.Session
+	for _, h := range p.hosts {
+		h.mu.Lock()
+		sessions = append(sessions, h.sessions...)
+		h.mu.Unlock()
+	}
+	return sessions
+}
+
+// Status returns the state of every host, in the order given to Start.
+func (p *Pool) Status(now time.Time) []HostStatus {
+	statuses := make([]HostStatus, len(p.hosts))
+	for i, h := range p.hosts {
+		h.mu.Lock()
+		status := h.status
+		h.mu.Unlock()
+
+		if status.State == HostConnected && now.Sub(status.LastSeen) > staleAfter {
+			status.State = HostStale
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// run keeps an agent running for h until ctx is cancelled.
+func (p *Pool) run(ctx context.Context, h *host) {
+	backoff := minBackoff
+	for {
+		err := p.stream(ctx, h)
+		if ctx.Err() != nil {
+			return
+		}
+
+		h.mu.Lock()
+		if h.status.State == HostConnected {
+			// The connection worked, so retry promptly
+			backoff = minBackoff
+		} else {
+			h.status.Failures++
+		}
+		h.status.State = HostDisconnected
+		h.status.Err = err.Error()
+		h.status.RetryAt = time.Now().Add(backoff)
+		h.sessions = nil
+		h.status.Sessions = 0
+		h.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// stream starts one agent and reads snapshots until it exits.
+func (p *Pool) stream(ctx context.Context, h *host) error {
+	cmd := p.dial(ctx, h.target)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = waitDelay
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	// Killing the agent does not close stdout if a child of it still holds
+	// the pipe, so close it directly to unblock the reader on shutdown
+	stop := context.AfterFunc(ctx, func() { stdout.Close() })
+	defer stop()
+
+	h.mu.Lock()
+	h.startedAt = time.Now()
+	h.status.State = HostConnecting
+	h.mu.Unlock()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxSnapshotBytes)
+	for scanner.Scan() {
+		var snapshot session.Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			continue
+		}
+		h.receive(snapshot, time.Now())
+	}
+	scanErr := scanner.Err()
+
+	waitErr := cmd.Wait()
+	switch {
+	case scanErr != nil:
+		return scanErr
+	case stderr.Len() > 0:
+		return errors.New(lastLine(stderr.String()))
+	case waitErr != nil:
+		return waitErr
+	default:
+		return fmt.Errorf("agent exited")
+	}
+}
+
+// receive stores a snapshot's sessions, tagged with the host.
+func (h *host) receive(snapshot session.Snapshot, now time.Time) {
+	sessions := make([]session.Session, len(snapshot.Sessions))
+	for i, s := range snapshot.Sessions {
+		s.Host = h.target
+		sessions[i] = s
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.status.State == HostConnecting {
+		h.status.Latency = now.Sub(h.startedAt)
+	}
+	h.status.State = HostConnected
+	h.status.LastSeen = now
+	h.status.Err = ""
+	h.status.Failures = 0
+	h.status.Sessions = len(sessions)
+	h.sessions = sessions
+}
+
+// WaitReady blocks until every host has either delivered a snapshot or
+// failed to connect, or until timeout.
+func (p *Pool) WaitReady(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		ready := true
+		for _, status := range p.Status(time.Now()) {
+			if status.State == HostConnecting {
+				ready = false
+			}
+		}
+		if ready {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// HostLabel returns the host part of an ssh target: user@box becomes box.
+func HostLabel(target string) string {
+	if index := strings.LastIndexByte(target, '@'); index != -1 {
+		return target[index+1:]
+	}
+	return target
+}
+
+// lastLine returns the last non-empty line of s, trimmed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
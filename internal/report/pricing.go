@@ -0,0 +1,52 @@
+package report
+
+import (
+	"strings"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// price is a model's list price in USD per million tokens.
+type price struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// prices maps model ID prefixes to list prices. The longest matching prefix
+// wins, so specific versions can override a family default. Costs are
+// estimates: cache writes are priced at the 5-minute TTL rate.
+var prices = []struct {
+	prefix string
+	price  price
+}{
+	{"claude-opus-4-5", price{5, 25, 6.25, 0.50}},
+	{"claude-opus-4", price{15, 75, 18.75, 1.50}},
+	{"claude-3-opus", price{15, 75, 18.75, 1.50}},
+	{"claude-sonnet-4", price{3, 15, 3.75, 0.30}},
+	{"claude-3-7-sonnet", price{3, 15, 3.75, 0.30}},
+	{"claude-3-5-sonnet", price{3, 15, 3.75, 0.30}},
+	{"claude-haiku-4", price{1, 5, 1.25, 0.10}},
+	{"claude-3-5-haiku", price{0.80, 4, 1, 0.08}},
+	{"claude-3-haiku", price{0.25, 1.25, 0.30, 0.03}},
+}
+
+// EstimateCost returns the estimated USD cost of usage on model and whether
+// the model has a known price. Unknown models cost zero.
+func EstimateCost(model string, usage session.Usage) (float64, bool) {
+	var best price
+	bestLength := 0
+	for _, entry := range prices {
+		if strings.HasPrefix(model, entry.prefix) && len(entry.prefix) > bestLength {
+			best = entry.price
+			bestLength = len(entry.prefix)
+		}
+	}
+	if bestLength == 0 {
+		return 0, false
+	}
+
+	cost := float64(usage.InputTokens)*best.input +
+		float64(usage.OutputTokens)*best.output +
+		float64(usage.CacheCreationTokens)*best.cacheWrite +
+		float64(usage.CacheReadTokens)*best.cacheRead
+	return cost / 1_000_000, true
+}
@@ -0,0 +1,250 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"table", "json", "csv", "markdown"}
+
+// section is one titled table of a rendered report.
+type section struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+// Write renders the report to w in the given format.
+func Write(w io.Writer, r *Report, format string) error {
+	switch format {
+	case "table":
+		return writeTable(w, r)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "csv":
+		return writeCSV(w, r)
+	case "markdown", "md":
+		return writeMarkdown(w, r)
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// summaryLines returns the headline figures shown above the tables.
+func summaryLines(r *Report) []string {
+	lines := []string{
+		fmt.Sprintf("Period:            %s → %s", r.Since.Local().Format("2006-01-02 15:04"), r.Until.Local().Format("2006-01-02 15:04")),
+		fmt.Sprintf("Sessions:          %d", r.Sessions),
+		fmt.Sprintf("Tokens:            %s", session.FormatTokens(r.Usage.Total())),
+		fmt.Sprintf("Estimated cost:    %s", formatCost(r.Cost)),
+		fmt.Sprintf("Average session:   %s", session.FormatDuration(r.AverageDuration)),
+		fmt.Sprintf("Waiting on human:  %s", session.FormatDuration(r.WaitingOnHuman)),
+	}
+	if len(r.Unpriced) > 0 {
+		lines = append(lines, fmt.Sprintf("Unpriced models:   %s", strings.Join(r.Unpriced, ", ")))
+	}
+	return lines
+}
+
+// sections lays out the report's tables.
+func sections(r *Report) []section {
+	projects := section{
+		title:  "Projects",
+		header: []string{"PROJECT", "SESSIONS", "TOKENS", "COST", "AVG LENGTH", "WAITING"},
+	}
+	for _, project := range r.Projects {
+		average := time.Duration(0)
+		if project.Sessions > 0 {
+			average = project.Duration / time.Duration(project.Sessions)
+		}
+		projects.rows = append(projects.rows, []string{
+			project.Project,
+			strconv.Itoa(project.Sessions),
+			session.FormatTokens(project.Usage.Total()),
+			formatCost(project.Cost),
+			session.FormatDuration(average),
+			session.FormatDuration(project.WaitingOnHuman),
+		})
+	}
+
+	models := section{
+		title:  "Models",
+		header: []string{"MODEL", "MESSAGES", "INPUT", "OUTPUT", "CACHE WRITE", "CACHE READ", "COST"},
+	}
+	for _, model := range r.Models {
+		cost := formatCost(model.Cost)
+		if !model.Priced {
+			cost = "?"
+		}
+		models.rows = append(models.rows, []string{
+			model.Model,
+			strconv.Itoa(model.Messages),
+			session.FormatTokens(model.Usage.InputTokens),
+			session.FormatTokens(model.Usage.OutputTokens),
+			session.FormatTokens(model.Usage.CacheCreationTokens),
+			session.FormatTokens(model.Usage.CacheReadTokens),
+			cost,
+		})
+	}
+
+	days := section{
+		title:  "Sessions per day",
+		header: []string{"DATE", "PROJECT", "SESSIONS"},
+	}
+	for _, day := range r.Days {
+		days.rows = append(days.rows, []string{day.Date, day.Project, strconv.Itoa(day.Sessions)})
+	}
+
+	tools := section{
+		title:  "Tools",
+		header: []string{"TOOL", "CALLS"},
+	}
+	for _, tool := range r.Tools {
+		tools.rows = append(tools.rows, []string{tool.Name, strconv.Itoa(tool.Calls)})
+	}
+
+	return []section{projects, models, days, tools}
+}
+
+// writeTable renders aligned plain-text tables for the terminal.
+func writeTable(w io.Writer, r *Report) error {
+	for _, line := range summaryLines(r) {
+		fmt.Fprintln(w, line)
+	}
+
+	for _, s := range sections(r) {
+		if len(s.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\n", s.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(s.header, "\t"))
+		for _, row := range s.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown renders the summary as a list and each section as a
+// pipe table.
+func writeMarkdown(w io.Writer, r *Report) error {
+	fmt.Fprintf(w, "# cctop usage report\n\n")
+	for _, line := range summaryLines(r) {
+		label, value, _ := strings.Cut(line, ":")
+		fmt.Fprintf(w, "- **%s:** %s\n", label, strings.TrimSpace(value))
+	}
+
+	for _, s := range sections(r) {
+		if len(s.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", s.title)
+		fmt.Fprintf(w, "| %s |\n", strings.Join(s.header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(s.header)))
+		for _, row := range s.rows {
+			escaped := make([]string, len(row))
+			for i, cell := range row {
+				escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+		}
+	}
+	return nil
+}
+
+// csvHeader is the single schema shared by every CSV row. Each row fills
+// the columns relevant to its section; token counts and durations are raw
+// numbers (durations in seconds) for spreadsheet use.
+var csvHeader = []string{
+	"section", "name", "date", "sessions", "messages", "calls",
+	"input_tokens", "output_tokens", "cache_creation_tokens", "cache_read_tokens",
+	"estimated_cost_usd", "duration_seconds", "waiting_seconds",
+}
+
+// writeCSV renders the report as one long-format CSV table.
+func writeCSV(w io.Writer, r *Report) error {
+	writer := csv.NewWriter(w)
+	row := func(values map[string]string) []string {
+		record := make([]string, len(csvHeader))
+		for i, column := range csvHeader {
+			record[i] = values[column]
+		}
+		return record
+	}
+	usageValues := func(values map[string]string, usage session.Usage, cost float64) map[string]string {
+		values["input_tokens"] = strconv.Itoa(usage.InputTokens)
+		values["output_tokens"] = strconv.Itoa(usage.OutputTokens)
+		values["cache_creation_tokens"] = strconv.Itoa(usage.CacheCreationTokens)
+		values["cache_read_tokens"] = strconv.Itoa(usage.CacheReadTokens)
+		values["estimated_cost_usd"] = strconv.FormatFloat(cost, 'f', 4, 64)
+		return values
+	}
+	seconds := func(d time.Duration) string { return strconv.FormatFloat(d.Seconds(), 'f', 0, 64) }
+
+	records := [][]string{csvHeader}
+	records = append(records, row(usageValues(map[string]string{
+		"section":          "total",
+		"sessions":         strconv.Itoa(r.Sessions),
+		"duration_seconds": seconds(r.TotalDuration),
+		"waiting_seconds":  seconds(r.WaitingOnHuman),
+	}, r.Usage, r.Cost)))
+	for _, project := range r.Projects {
+		records = append(records, row(usageValues(map[string]string{
+			"section":          "project",
+			"name":             project.Project,
+			"sessions":         strconv.Itoa(project.Sessions),
+			"duration_seconds": seconds(project.Duration),
+			"waiting_seconds":  seconds(project.WaitingOnHuman),
+		}, project.Usage, project.Cost)))
+	}
+	for _, model := range r.Models {
+		records = append(records, row(usageValues(map[string]string{
+			"section":  "model",
+			"name":     model.Model,
+			"messages": strconv.Itoa(model.Messages),
+		}, model.Usage, model.Cost)))
+	}
+	for _, day := range r.Days {
+		records = append(records, row(map[string]string{
+			"section":  "day",
+			"name":     day.Project,
+			"date":     day.Date,
+			"sessions": strconv.Itoa(day.Sessions),
+		}))
+	}
+	for _, tool := range r.Tools {
+		records = append(records, row(map[string]string{
+			"section": "tool",
+			"name":    tool.Name,
+			"calls":   strconv.Itoa(tool.Calls),
+		}))
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// formatCost renders a USD amount with cents, or more precision below $1.
+func formatCost(cost float64) string {
+	if cost > 0 && cost < 1 {
+		return fmt.Sprintf("$%.3f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -0,0 +1,380 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// Report aggregates usage statistics over all transcripts active since a
+// cutoff time.
+type Report struct {
+	Since           time.Time      `json:"since"`
+	Until           time.Time      `json:"until"`
+	Sessions        int            `json:"sessions"`
+	Usage           session.Usage  `json:"usage"`
+	Cost            float64        `json:"estimated_cost_usd"`
+	TotalDuration   time.Duration  `json:"total_duration_ns"`
+	AverageDuration time.Duration  `json:"average_duration_ns"`
+	WaitingOnHuman  time.Duration  `json:"waiting_on_human_ns"`
+	Projects        []ProjectStats `json:"projects"`
+	Models          []ModelStats   `json:"models"`
+	Days            []DayStats     `json:"days"`
+	Tools           []ToolStats    `json:"tools"`
+	Unpriced        []string       `json:"unpriced_models,omitempty"` // Models with usage but no known price
+	byProject       map[string]*ProjectStats
+	byModel         map[string]*ModelStats
+	byDay           map[[2]string]*DayStats
+	byTool          map[string]*ToolStats
+}
+
+// ProjectStats aggregates the sessions of one project.
+type ProjectStats struct {
+	Project        string        `json:"project"`
+	Sessions       int           `json:"sessions"`
+	Usage          session.Usage `json:"usage"`
+	Cost           float64       `json:"estimated_cost_usd"`
+	Duration       time.Duration `json:"duration_ns"`
+	WaitingOnHuman time.Duration `json:"waiting_on_human_ns"`
+}
+
+// ModelStats aggregates assistant messages produced by one model.
+type ModelStats struct {
+	Model    string        `json:"model"`
+	Messages int           `json:"messages"`
+	Usage    session.Usage `json:"usage"`
+	Cost     float64       `json:"estimated_cost_usd"`
+	Priced   bool          `json:"priced"`
+}
+
+// DayStats counts the sessions of one project started on one local date.
+type DayStats struct {
+	Date     string `json:"date"` // YYYY-MM-DD in local time
+	Project  string `json:"project"`
+	Sessions int    `json:"sessions"`
+}
+
+// ToolStats counts calls to one tool.
+type ToolStats struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+}
+
+// transcriptLine holds the fields of a transcript line used for reporting.
+type transcriptLine struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	CWD       string `json:"cwd"`
+	IsMeta    bool   `json:"isMeta"`
+	Message   struct {
+		ID      string          `json:"id"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+		Usage   *session.Usage  `json:"usage"`
+	} `json:"message"`
+}
+
+// contentBlock is one element of a structured message.content array.
+type contentBlock struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// transcriptSummary is what one transcript contributes to a report.
+type transcriptSummary struct {
+	cwd            string
+	first, last    time.Time // Earliest and latest timestamps inside the window
+	waitingOnHuman time.Duration
+	models         map[string]*ModelStats
+	tools          map[string]int
+}
+
+// Build walks <claudeDir>/projects/*/*.jsonl of each of claudeDirs and
+// aggregates every transcript line timestamped at or after since.
+// Transcripts not modified since the cutoff are skipped without being read.
+func Build(claudeDirs []string, since, now time.Time) (*Report, error) {
+	var matches []string
+	for _, claudeDir := range claudeDirs {
+		dirMatches, err := filepath.Glob(filepath.Join(claudeDir, "projects", "*", "*.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, dirMatches...)
+	}
+
+	report := &Report{
+		Since:     since,
+		Until:     now,
+		byProject: make(map[string]*ProjectStats),
+		byModel:   make(map[string]*ModelStats),
+		byDay:     make(map[[2]string]*DayStats),
+		byTool:    make(map[string]*ToolStats),
+	}
+
+	for _, transcriptPath := range matches {
+		info, statErr := os.Stat(transcriptPath)
+		if statErr != nil || info.ModTime().Before(since) {
+			continue
+		}
+
+		summary, readErr := summarizeTranscript(transcriptPath, since)
+		if readErr != nil || summary.first.IsZero() {
+			continue
+		}
+		report.add(summary)
+	}
+
+	report.finish()
+	return report, nil
+}
+
+// summarizeTranscript reads one transcript, keeping lines inside the window.
+func summarizeTranscript(transcriptPath string, since time.Time) (*transcriptSummary, error) {
+	file, err := os.Open(transcriptPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	summary := &transcriptSummary{
+		models: make(map[string]*ModelStats),
+		tools:  make(map[string]int),
+	}
+	seenMessages := make(map[string]bool)
+	var lastAssistant time.Time // Zero unless the latest turn ended with the assistant
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		raw, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, readErr
+		}
+
+		var line transcriptLine
+		if len(raw) > 0 && json.Unmarshal(raw, &line) == nil && (line.Type == "user" || line.Type == "assistant") {
+			timestamp, parseErr := time.Parse(time.RFC3339Nano, line.Timestamp)
+			if parseErr == nil && !timestamp.Before(since) {
+				if summary.cwd == "" {
+					summary.cwd = line.CWD
+				}
+				if summary.first.IsZero() {
+					summary.first = timestamp
+				}
+				if timestamp.After(summary.last) {
+					summary.last = timestamp
+				}
+
+				if line.Type == "assistant" {
+					summary.addAssistant(line, seenMessages)
+					lastAssistant = timestamp
+				} else if !line.IsMeta {
+					if isHumanPrompt(line.Message.Content) {
+						if !lastAssistant.IsZero() && timestamp.After(lastAssistant) {
+							summary.waitingOnHuman += timestamp.Sub(lastAssistant)
+						}
+					}
+					lastAssistant = time.Time{}
+				}
+			}
+		}
+
+		if readErr != nil {
+			return summary, nil
+		}
+	}
+}
+
+// addAssistant records an assistant line's model usage and tool calls.
+// Usage is counted once per message ID, since one message is split across
+// several lines that repeat its usage block.
+func (summary *transcriptSummary) addAssistant(line transcriptLine, seenMessages map[string]bool) {
+	var blocks []contentBlock
+	if len(line.Message.Content) > 0 && line.Message.Content[0] == '[' {
+		_ = json.Unmarshal(line.Message.Content, &blocks)
+	}
+	for _, block := range blocks {
+		if block.Type == "tool_use" && block.Name != "" {
+			summary.tools[block.Name]++
+		}
+	}
+
+	if line.Message.Usage == nil || line.Message.Model == "" || line.Message.Model == "<synthetic>" {
+		return
+	}
+	if line.Message.ID != "" {
+		if seenMessages[line.Message.ID] {
+			return
+		}
+		seenMessages[line.Message.ID] = true
+	}
+
+	stats, ok := summary.models[line.Message.Model]
+	if !ok {
+		stats = &ModelStats{Model: line.Message.Model}
+		summary.models[line.Message.Model] = stats
+	}
+	stats.Messages++
+	stats.Usage = stats.Usage.Add(*line.Message.Usage)
+}
+
+// isHumanPrompt reports whether user message content was typed by a person
+// rather than being a tool result fed back to the model.
+func isHumanPrompt(content json.RawMessage) bool {
+	if len(content) == 0 {
+		return false
+	}
+	if content[0] == '"' {
+		return true
+	}
+
+	var blocks []contentBlock
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return false
+	}
+	for _, block := range blocks {
+		if block.Type == "tool_result" {
+			return false
+		}
+	}
+	return len(blocks) > 0
+}
+
+// add merges one transcript's summary into the report.
+func (r *Report) add(summary *transcriptSummary) {
+	projectName := "(unknown)"
+	if summary.cwd != "" {
+		projectName = session.ShortProjectName(summary.cwd)
+	}
+	duration := summary.last.Sub(summary.first)
+
+	r.Sessions++
+	r.TotalDuration += duration
+	r.WaitingOnHuman += summary.waitingOnHuman
+
+	project, ok := r.byProject[projectName]
+	if !ok {
+		project = &ProjectStats{Project: projectName}
+		r.byProject[projectName] = project
+	}
+	project.Sessions++
+	project.Duration += duration
+	project.WaitingOnHuman += summary.waitingOnHuman
+
+	dayKey := [2]string{summary.first.Local().Format("2006-01-02"), projectName}
+	day, ok := r.byDay[dayKey]
+	if !ok {
+		day = &DayStats{Date: dayKey[0], Project: projectName}
+		r.byDay[dayKey] = day
+	}
+	day.Sessions++
+
+	for name, stats := range summary.models {
+		cost, priced := EstimateCost(name, stats.Usage)
+
+		model, ok := r.byModel[name]
+		if !ok {
+			model = &ModelStats{Model: name, Priced: priced}
+			r.byModel[name] = model
+		}
+		model.Messages += stats.Messages
+		model.Usage = model.Usage.Add(stats.Usage)
+		model.Cost += cost
+
+		project.Usage = project.Usage.Add(stats.Usage)
+		project.Cost += cost
+		r.Usage = r.Usage.Add(stats.Usage)
+		r.Cost += cost
+	}
+
+	for name, calls := range summary.tools {
+		tool, ok := r.byTool[name]
+		if !ok {
+			tool = &ToolStats{Name: name}
+			r.byTool[name] = tool
+		}
+		tool.Calls += calls
+	}
+}
+
+// finish flattens the aggregation maps into sorted slices.
+func (r *Report) finish() {
+	if r.Sessions > 0 {
+		r.AverageDuration = r.TotalDuration / time.Duration(r.Sessions)
+	}
+
+	r.Projects = make([]ProjectStats, 0, len(r.byProject))
+	for _, project := range r.byProject {
+		r.Projects = append(r.Projects, *project)
+	}
+	sort.Slice(r.Projects, func(i, j int) bool {
+		if r.Projects[i].Sessions != r.Projects[j].Sessions {
+			return r.Projects[i].Sessions > r.Projects[j].Sessions
+		}
+		return r.Projects[i].Project < r.Projects[j].Project
+	})
+
+	r.Models = make([]ModelStats, 0, len(r.byModel))
+	for _, model := range r.byModel {
+		r.Models = append(r.Models, *model)
+		if !model.Priced {
+			r.Unpriced = append(r.Unpriced, model.Model)
+		}
+	}
+	sort.Slice(r.Models, func(i, j int) bool {
+		if r.Models[i].Usage.Total() != r.Models[j].Usage.Total() {
+			return r.Models[i].Usage.Total() > r.Models[j].Usage.Total()
+		}
+		return r.Models[i].Model < r.Models[j].Model
+	})
+	sort.Strings(r.Unpriced)
+
+	r.Days = make([]DayStats, 0, len(r.byDay))
+	for _, day := range r.byDay {
+		r.Days = append(r.Days, *day)
+	}
+	sort.Slice(r.Days, func(i, j int) bool {
+		if r.Days[i].Date != r.Days[j].Date {
+			return r.Days[i].Date < r.Days[j].Date
+		}
+		return r.Days[i].Project < r.Days[j].Project
+	})
+
+	r.Tools = make([]ToolStats, 0, len(r.byTool))
+	for _, tool := range r.byTool {
+		r.Tools = append(r.Tools, *tool)
+	}
+	sort.Slice(r.Tools, func(i, j int) bool {
+		if r.Tools[i].Calls != r.Tools[j].Calls {
+			return r.Tools[i].Calls > r.Tools[j].Calls
+		}
+		return r.Tools[i].Name < r.Tools[j].Name
+	})
+}
+
+// ParseSince resolves a --since value relative to now. It accepts a day
+// count ("7d"), a Go duration ("36h", "90m") or a local date ("2025-01-31").
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if count, err := strconv.Atoi(days); err == nil && count >= 0 {
+			return now.AddDate(0, 0, -count), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (want e.g. 7d, 12h or 2025-01-31)", value)
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// cacheVersion is bumped whenever the on-disk format or the extraction rules
+// change, forcing a full rebuild.
+const cacheVersion = 1
+
+// Document is one searchable piece of a transcript: a user prompt, an
+// assistant reply, or a tool input.
+type Document struct {
+	Timestamp time.Time
+	Kind      string // "user", "assistant", or the tool name
+	Text      string
+}
+
+// fileRecord holds the extracted documents of one transcript and how far
+// into the file extraction has progressed.
+type fileRecord struct {
+	SessionID string
+	CWD       string
+	Offset    int64 // Byte offset just past the last extracted line
+	Documents []Document
+}
+
+// Cache holds the searchable documents of all transcripts, persisted to disk
+// and updated incrementally by reading only bytes appended since the last
+// update. It saves re-parsing transcripts, not scanning them: Search checks
+// every cached document, so its cost grows with the total text cached.
+type Cache struct {
+	Version int
+	Files   map[string]*fileRecord // Keyed by transcript path
+
+	path string
+}
+
+// DefaultPath returns the on-disk location of the search cache.
+func DefaultPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheDir, "cctop", "search-cache.gob")
+}
+
+// Open loads the cache at path, or returns an empty cache if the file does
+// not exist, is unreadable, or was written by an incompatible version.
+func Open(path string) *Cache {
+	cache := &Cache{Version: cacheVersion, Files: make(map[string]*fileRecord), path: path}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return cache
+	}
+	defer file.Close()
+
+	var loaded Cache
+	if decodeErr := gob.NewDecoder(file).Decode(&loaded); decodeErr != nil || loaded.Version != cacheVersion {
+		return cache
+	}
+
+	loaded.path = path
+	if loaded.Files == nil {
+		loaded.Files = make(map[string]*fileRecord)
+	}
+	return &loaded
+}
+
+// Save writes the cache to disk atomically. The whole cache is rewritten, so
+// the cost grows with every cached transcript rather than with what changed
+// (documents are capped at maxDocumentLength, which keeps a large history at
+// tens of megabytes); callers save only when Update reports a change.
+func (c *Cache) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := c.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if encodeErr := gob.NewEncoder(file).Encode(c); encodeErr != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return encodeErr
+	}
+	if closeErr := file.Close(); closeErr != nil {
+		os.Remove(tmpPath)
+		return closeErr
+	}
+	return os.Rename(tmpPath, c.path)
+}
+
+// Update brings the cache in sync with the transcripts under
+// <claudeDir>/projects of each of claudeDirs. New lines appended to known
+// transcripts are parsed from the recorded offset; truncated or rewritten
+// transcripts are re-read from the start; transcripts that are deleted, or
+// outside claudeDirs, are dropped. It reports whether any record changed.
+func (c *Cache) Update(claudeDirs ...string) (bool, error) {
+	var matches []string
+	for _, claudeDir := range claudeDirs {
+		dirMatches, err := filepath.Glob(filepath.Join(claudeDir, "projects", "*", "*.jsonl"))
+		if err != nil {
+			return false, err
+		}
+		matches = append(matches, dirMatches...)
+	}
+
+	changed := false
+	present := make(map[string]bool, len(matches))
+
+	for _, transcriptPath := range matches {
+		present[transcriptPath] = true
+
+		info, statErr := os.Stat(transcriptPath)
+		if statErr != nil {
+			continue
+		}
+
+		record, known := c.Files[transcriptPath]
+		if !known || info.Size() < record.Offset {
+			record = &fileRecord{SessionID: session.SessionIDFromPath(transcriptPath)}
+			c.Files[transcriptPath] = record
+			changed = true
+		}
+		if info.Size() == record.Offset {
+			continue
+		}
+
+		if extractErr := extractAppended(transcriptPath, record); extractErr != nil {
+			continue
+		}
+		changed = true
+	}
+
+	for transcriptPath := range c.Files {
+		if !present[transcriptPath] {
+			delete(c.Files, transcriptPath)
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// extractAppended parses complete lines written after record.Offset and
+// appends their documents. A trailing partial line is left for the next
+// update.
+func extractAppended(transcriptPath string, record *fileRecord) error {
+	file, err := os.Open(transcriptPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, seekErr := file.Seek(record.Offset, io.SeekStart); seekErr != nil {
+		return seekErr
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if errors.Is(readErr, io.EOF) {
+			// A final line without a newline is only extracted once it is
+			// complete JSON; otherwise the writer is mid-line, so wait
+			if len(line) == 0 || !json.Valid(line) {
+				return nil
+			}
+		} else if readErr != nil {
+			return readErr
+		}
+
+		record.Offset += int64(len(line))
+
+		cwd, documents := extractDocuments(line)
+		if record.CWD == "" {
+			record.CWD = cwd
+		}
+		record.Documents = append(record.Documents, documents...)
+
+		if readErr != nil {
+			return nil
+		}
+	}
+}
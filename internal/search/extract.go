@@ -0,0 +1,103 @@
+package search
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// maxDocumentLength caps the cached text of a single document so that huge
+// pastes or file contents do not bloat the cache.
+const maxDocumentLength = 8000
+
+// toolInputKeys lists the tool_use input fields worth searching: paths,
+// commands, patterns and short prose. File contents are deliberately skipped.
+var toolInputKeys = []string{"file_path", "notebook_path", "path", "command", "pattern", "url", "query", "description", "prompt"}
+
+// transcriptLine holds the fields of a transcript line used for searching.
+type transcriptLine struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	CWD       string `json:"cwd"`
+	Message   struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// contentBlock is one element of a structured message.content array.
+type contentBlock struct {
+	Type  string                     `json:"type"`
+	Text  string                     `json:"text"`
+	Name  string                     `json:"name"`
+	Input map[string]json.RawMessage `json:"input"`
+}
+
+// extractDocuments parses one transcript line into searchable documents:
+// user prompts, assistant text, and tool inputs.
+func extractDocuments(raw []byte) (string, []Document) {
+	var line transcriptLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return "", nil
+	}
+	if line.Type != "user" && line.Type != "assistant" {
+		return line.CWD, nil
+	}
+
+	timestamp, _ := time.Parse(time.RFC3339Nano, line.Timestamp)
+	var documents []Document
+	addDocument := func(kind string, text string) {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			return
+		}
+		if len(text) > maxDocumentLength {
+			// Back up to a character boundary so no invalid UTF-8 is stored
+			cut := maxDocumentLength
+			for cut > 0 && !isRuneStart(text[cut]) {
+				cut--
+			}
+			text = text[:cut]
+		}
+		documents = append(documents, Document{Timestamp: timestamp, Kind: kind, Text: text})
+	}
+
+	// String content: a plain prompt or reply
+	var textContent string
+	if err := json.Unmarshal(line.Message.Content, &textContent); err == nil {
+		addDocument(line.Type, textContent)
+		return line.CWD, documents
+	}
+
+	var blocks []contentBlock
+	if err := json.Unmarshal(line.Message.Content, &blocks); err != nil {
+		return line.CWD, nil
+	}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			addDocument(line.Type, block.Text)
+		case "tool_use":
+			addDocument(block.Name, toolInputText(block.Input))
+		}
+	}
+
+	return line.CWD, documents
+}
+
+// toolInputText joins the searchable string fields of a tool input.
+func toolInputText(input map[string]json.RawMessage) string {
+	var parts []string
+	for _, key := range toolInputKeys {
+		raw, ok := input[key]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil && value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// snippetContext is how many characters of context precede a match in a snippet.
+const snippetContext = 40
+
+// Result is one matching document.
+type Result struct {
+	SessionID  string
+	Path       string // Transcript path
+	CWD        string
+	Project    string
+	Timestamp  time.Time
+	Kind       string
+	Snippet    string // Excerpt of the document around the first match
+	MatchStart int    // Byte offset of the match within Snippet
+	MatchEnd   int
+}
+
+// Search returns documents containing every whitespace-separated query term
+// (case-insensitive), newest first, up to limit results (0 = unlimited). It
+// scans every cached document, so it takes time linear in the cached text.
+func (c *Cache) Search(query string, limit int) []Result {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []Result
+	for transcriptPath, record := range c.Files {
+		for _, document := range record.Documents {
+			lowerText := strings.ToLower(document.Text)
+			if !containsAll(lowerText, terms) {
+				continue
+			}
+
+			snippet, matchStart, matchEnd := makeSnippet(document.Text, lowerText, terms[0])
+			result := Result{
+				SessionID:  record.SessionID,
+				Path:       transcriptPath,
+				CWD:        record.CWD,
+				Timestamp:  document.Timestamp,
+				Kind:       document.Kind,
+				Snippet:    snippet,
+				MatchStart: matchStart,
+				MatchEnd:   matchEnd,
+			}
+			if record.CWD != "" {
+				result.Project = session.ShortProjectName(record.CWD)
+			}
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].Timestamp.Equal(results[j].Timestamp) {
+			return results[i].Timestamp.After(results[j].Timestamp)
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// containsAll reports whether text contains every term.
+func containsAll(text string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// makeSnippet cuts an excerpt starting shortly before the first occurrence of
+// term, returning the excerpt and the match bounds within it. lowerText must
+// be strings.ToLower(text) with identical byte offsets, which holds for the
+// ASCII search terms typical of paths and commands.
+func makeSnippet(text string, lowerText string, term string) (string, int, int) {
+	matchIdx := strings.Index(lowerText, term)
+	if matchIdx < 0 || len(lowerText) != len(text) {
+		return text, 0, 0
+	}
+
+	start := 0
+	prefix := ""
+	if matchIdx > snippetContext {
+		start = matchIdx - snippetContext
+		// Avoid cutting through a multi-byte character
+		for start < matchIdx && !isRuneStart(text[start]) {
+			start++
+		}
+		prefix = "…"
+	}
+
+	snippet := prefix + text[start:]
+	matchStart := len(prefix) + matchIdx - start
+	return snippet, matchStart, matchStart + len(term)
+}
+
+// isRuneStart reports whether b begins a UTF-8 encoded character.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
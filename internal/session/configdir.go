@@ -0,0 +1,79 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// ConfigDirEnv is the environment variable Claude Code reads to use a config
+// directory other than ~/.claude.
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// ConfigDirs returns the config directories discovery searches: the default
+// one first, then extra (from --claude-dir), with duplicates removed.
+func ConfigDirs(extra []string) []string {
+	dirs := []string{DefaultClaudeDir()}
+	seen := map[string]bool{dirs[0]: true}
+	for _, dir := range extra {
+		dir = filepath.Clean(dir)
+		if dir != "." && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// processConfigDir returns the CLAUDE_CONFIG_DIR a process was started
+// with, or "" when it is unset or the environment cannot be read. Only Linux
+// exposes other processes' environments without privileges.
+func processConfigDir(pid int) string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	dir := processEnv(pid)[ConfigDirEnv]
+	if dir == "" {
+		return ""
+	}
+	return filepath.Clean(dir)
+}
+
+// assignConfigDirs sets each session's ClaudeDir when its transcripts live
+// outside the default directory. The process's own CLAUDE_CONFIG_DIR wins;
+// otherwise, with several directories to choose from, the one holding the
+// most recently written transcript for the session's CWD is used. Sessions
+// already assigned (containers, IDE lock files) are left alone.
+func assignConfigDirs(sessions []Session, dirs []string) {
+	for i := range sessions {
+		s := &sessions[i]
+		if s.ClaudeDir != "" {
+			continue
+		}
+		dir := processConfigDir(s.PID)
+		if dir == "" && len(dirs) > 1 {
+			dir = newestProjectDir(dirs, s.CWD)
+		}
+		if dir != "" && dir != dirs[0] {
+			s.ClaudeDir = dir
+		}
+	}
+}
+
+// newestProjectDir returns the config directory holding the most recently
+// written transcript for cwd, or "" when none has one.
+func newestProjectDir(dirs []string, cwd string) string {
+	best := ""
+	var bestTime time.Time
+	for _, dir := range dirs {
+		transcripts, _ := filepath.Glob(filepath.Join(dir, "projects", EncodePath(cwd), "*.jsonl"))
+		for _, transcript := range transcripts {
+			info, err := os.Stat(transcript)
+			if err == nil && info.ModTime().After(bestTime) {
+				best, bestTime = dir, info.ModTime()
+			}
+		}
+	}
+	return best
+}
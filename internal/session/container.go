@@ -0,0 +1,376 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Container describes the container a process runs in.
+type Container struct {
+	ID      string // Runtime container ID; empty when only a marker file identified it
+	Name    string
+	Runtime string      // CLI that manages the container: "docker" or "podman"
+	PID     int         // Process whose view of the filesystem is used
+	Mounts  []BindMount // Host directories visible inside the container, longest path first
+}
+
+// BindMount maps a host directory to where it appears inside a container.
+type BindMount struct {
+	HostPath      string
+	ContainerPath string
+}
+
+// MountInfo is one line of /proc/<pid>/mountinfo.
+type MountInfo struct {
+	Device     string // major:minor of the mounted filesystem
+	Root       string // Directory within the filesystem that is mounted
+	MountPoint string
+	FSType     string
+}
+
+// containerIDPattern matches a full container ID.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// containerRuntimeHints are the path fragments that mark a cgroup or mount
+// source as belonging to a container runtime.
+var containerRuntimeHints = []string{"docker", "libpod", "containerd", "crio", "kubepods", "/containers/"}
+
+// podmanHints are the path fragments that mark a container as podman's.
+var podmanHints = []string{"libpod", "/containers/storage/"}
+
+// containerIdentity is a container's resolved name and managing CLI.
+type containerIdentity struct {
+	name    string
+	runtime string
+}
+
+// containerIdentities caches resolved identities by container ID, since
+// resolving one may run the docker or podman CLI. Discovery runs from
+// several goroutines, so access is guarded by containerIdentitiesMu.
+var (
+	containerIdentities   = make(map[string]containerIdentity)
+	containerIdentitiesMu sync.Mutex
+)
+
+// ParseContainerID extracts a container ID from the contents of
+// /proc/<pid>/cgroup (docker-<id>.scope, /docker/<id>, kubepods paths) or,
+// for cgroup namespaces that hide it, /proc/<pid>/mountinfo (the
+// /var/lib/docker/containers/<id>/hostname bind mount). It also returns the
+// runtime the path names: "podman" for libpod and containers/storage paths,
+// otherwise "docker". Both are "" when the text names no container.
+func ParseContainerID(text string) (id string, runtime string) {
+	for _, line := range strings.Split(text, "\n") {
+		hinted := false
+		for _, hint := range containerRuntimeHints {
+			if strings.Contains(line, hint) {
+				hinted = true
+				break
+			}
+		}
+		if !hinted {
+			continue
+		}
+		if id := containerIDPattern.FindString(line); id != "" {
+			for _, hint := range podmanHints {
+				if strings.Contains(line, hint) {
+					return id, "podman"
+				}
+			}
+			return id, "docker"
+		}
+	}
+	return "", ""
+}
+
+// ParseMountInfo parses /proc/<pid>/mountinfo.
+func ParseMountInfo(data string) []MountInfo {
+	var mounts []MountInfo
+	for _, line := range strings.Split(data, "\n") {
+		// mountID parentID major:minor root mountPoint options [optional...] - fstype source superOptions
+		fields := strings.Fields(line)
+		separator := slices.Index(fields, "-")
+		if len(fields) < 5 || separator == -1 || separator+1 >= len(fields) {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			Device:     fields[2],
+			Root:       unescapeMountPath(fields[3]),
+			MountPoint: unescapeMountPath(fields[4]),
+			FSType:     fields[separator+1],
+		})
+	}
+	return mounts
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for space) mountinfo
+// uses for whitespace and backslashes in paths.
+func unescapeMountPath(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) {
+			if code, err := strconv.ParseUint(value[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// BindMounts maps a container's mounts to host paths. A container mount is
+// visible on the host when a host mount shows the same filesystem at a root
+// that contains it; the host path is that mount point plus the remainder.
+// The container's root filesystem is skipped. Mounts are ordered longest
+// container path first, so HostPath picks the most specific one.
+func BindMounts(container, host []MountInfo) []BindMount {
+	var mounts []BindMount
+	for _, mount := range container {
+		if mount.MountPoint == "/" {
+			continue
+		}
+
+		best := -1
+		for i, candidate := range host {
+			if candidate.Device != mount.Device || !pathWithin(mount.Root, candidate.Root) {
+				continue
+			}
+			if best == -1 || len(candidate.Root) > len(host[best].Root) {
+				best = i
+			}
+		}
+		if best == -1 {
+			continue
+		}
+
+		rel := strings.TrimPrefix(mount.Root, host[best].Root)
+		mounts = append(mounts, BindMount{
+			HostPath:      path.Join(host[best].MountPoint, rel),
+			ContainerPath: mount.MountPoint,
+		})
+	}
+
+	slices.SortStableFunc(mounts, func(a, b BindMount) int {
+		return len(b.ContainerPath) - len(a.ContainerPath)
+	})
+	return mounts
+}
+
+// HostPath translates a path inside the container to the host. Paths under
+// a bind mount map to the mounted host directory; anything else is reached
+// through the process's root at /proc/<pid>/root.
+func (c Container) HostPath(containerPath string) string {
+	for _, mount := range c.Mounts {
+		if pathWithin(containerPath, mount.ContainerPath) {
+			return path.Join(mount.HostPath, strings.TrimPrefix(containerPath, mount.ContainerPath))
+		}
+	}
+	return path.Join(fmt.Sprintf("/proc/%d/root", c.PID), containerPath)
+}
+
+// pathWithin reports whether p is dir or below it.
+func pathWithin(p, dir string) bool {
+	if dir == "/" {
+		return strings.HasPrefix(p, "/")
+	}
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// DetectContainer reports whether pid runs in a container other than the
+// one cctop itself runs in. A process qualifies when its mount namespace
+// differs from ours and either its cgroup or mounts name a container, or
+// its root holds a runtime marker file (/.dockerenv, /run/.containerenv).
+// hostMounts is cctop's own mountinfo, used to translate bind mounts.
+func DetectContainer(pid int, hostMounts []MountInfo) (Container, bool) {
+	if runtime.GOOS != "linux" {
+		return Container{}, false
+	}
+
+	self, selfErr := os.Readlink("/proc/self/ns/mnt")
+	namespace, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/mnt", pid))
+	if selfErr != nil || err != nil || namespace == self {
+		return Container{}, false
+	}
+
+	procDir := fmt.Sprintf("/proc/%d", pid)
+	mountData, _ := os.ReadFile(filepath.Join(procDir, "mountinfo"))
+	cgroupData, _ := os.ReadFile(filepath.Join(procDir, "cgroup"))
+
+	id, runtime := ParseContainerID(string(cgroupData))
+	if id == "" {
+		id, runtime = ParseContainerID(string(mountData))
+	}
+	if id == "" {
+		switch {
+		case fileExists(filepath.Join(procDir, "root", "run", ".containerenv")):
+			runtime = "podman"
+		case fileExists(filepath.Join(procDir, "root", ".dockerenv")):
+			runtime = "docker"
+		default:
+			return Container{}, false
+		}
+	}
+
+	identity := resolveContainer(pid, id, runtime)
+	return Container{
+		ID:      id,
+		Name:    identity.name,
+		Runtime: identity.runtime,
+		PID:     pid,
+		Mounts:  BindMounts(ParseMountInfo(string(mountData)), hostMounts),
+	}, true
+}
+
+// resolveContainer names a container by asking its runtime's CLI, trying
+// the other runtime when that fails; the CLI that knows the container
+// becomes its runtime. Without a CLI it falls back to the container's
+// hostname and then the short ID, keeping the runtime guessed from its
+// paths.
+func resolveContainer(pid int, id string, runtime string) containerIdentity {
+	if id != "" {
+		containerIdentitiesMu.Lock()
+		identity, ok := containerIdentities[id]
+		containerIdentitiesMu.Unlock()
+		if ok {
+			return identity
+		}
+	}
+
+	identity := containerIdentity{runtime: runtime}
+	if id != "" {
+		clis := []string{"docker", "podman"}
+		if runtime == "podman" {
+			clis = []string{"podman", "docker"}
+		}
+		for _, cli := range clis {
+			out, err := exec.Command(cli, "inspect", "--format", "{{.Name}}", id).Output()
+			if err == nil {
+				identity = containerIdentity{name: strings.TrimPrefix(strings.TrimSpace(string(out)), "/"), runtime: cli}
+				break
+			}
+		}
+	}
+	if identity.name == "" {
+		if hostname, err := os.ReadFile(fmt.Sprintf("/proc/%d/root/etc/hostname", pid)); err == nil {
+			identity.name = strings.TrimSpace(string(hostname))
+		}
+	}
+	if identity.name == "" && len(id) >= 12 {
+		identity.name = id[:12]
+	}
+	if identity.name == "" {
+		identity.name = "?"
+	}
+
+	if id != "" {
+		containerIdentitiesMu.Lock()
+		containerIdentities[id] = identity
+		containerIdentitiesMu.Unlock()
+	}
+	return identity
+}
+
+// processEnv reads a process's environment from /proc/<pid>/environ.
+func processEnv(pid int) map[string]string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/environ", pid))
+	if err != nil {
+		return nil
+	}
+	env := make(map[string]string)
+	for _, entry := range bytes.Split(data, []byte{0}) {
+		if key, value, ok := strings.Cut(string(entry), "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readHostMounts returns cctop's own mount table.
+func readHostMounts() []MountInfo {
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return nil
+	}
+	return ParseMountInfo(string(data))
+}
+
+// discoverContainerSessions splits off claude processes that run in
+// containers, whose working directories and ~/.claude are only meaningful
+// inside the container. Each becomes a session whose CWD is translated to
+// the host and whose transcript is looked up in the container's config
+// directory. The remaining host processes are returned for the usual CLI
+// and IDE discovery.
+func discoverContainerSessions(entries []psEntry, cwdMap map[int]string) ([]psEntry, []Session) {
+	if runtime.GOOS != "linux" {
+		return entries, nil
+	}
+
+	hostMounts := readHostMounts()
+	var hostEntries []psEntry
+	var sessions []Session
+	for _, entry := range entries {
+		container, ok := DetectContainer(entry.PID, hostMounts)
+		if !ok {
+			hostEntries = append(hostEntries, entry)
+			continue
+		}
+
+		commandParts := strings.Fields(entry.Command)
+		if len(commandParts) == 0 || filepath.Base(commandParts[0]) != "claude" {
+			continue
+		}
+		cwd := cwdMap[entry.PID]
+		if cwd == "" {
+			continue
+		}
+
+		env := processEnv(entry.PID)
+		configDir := env[ConfigDirEnv]
+		if configDir == "" {
+			home := env["HOME"]
+			if home == "" {
+				home = "/root"
+			}
+			configDir = path.Join(home, ".claude")
+		}
+
+		// The TTY is left empty: the container's pts numbers belong to its
+		// own devpts, so replying by TTY could reach an unrelated host pane
+		hostCWD := container.HostPath(cwd)
+		model, permissionMode := ParseLaunchFlags(entry.Command)
+		sessions = append(sessions, Session{
+			PID:              entry.PID,
+			CWD:              hostCWD,
+			Source:           Source{Type: "container:" + container.Name},
+			Project:          ShortProjectName(hostCWD),
+			Duration:         ParseEtime(entry.Etime),
+			Model:            model,
+			PermissionMode:   permissionMode,
+			Container:        container.Name,
+			ContainerRuntime: container.Runtime,
+			ContainerCWD:     cwd,
+			ClaudeDir:        container.HostPath(configDir),
+		})
+	}
+	return hostEntries, sessions
+}
@@ -0,0 +1,42 @@
+package session
+
+import "strings"
+
+const (
+	// DefaultContextWindow is the context window of current Claude models.
+	DefaultContextWindow = 200_000
+
+	// ExtendedContextWindow is the context window of models run with the
+	// 1M-token beta, selected with a [1m] model suffix.
+	ExtendedContextWindow = 1_000_000
+
+	// NearCompactionPercent is the context utilisation from which a session
+	// is flagged as close to auto-compaction.
+	NearCompactionPercent = 80
+)
+
+// ContextWindow returns the context window size for a model. A [1m] suffix
+// (as passed to --model) selects the extended window, as does a context
+// already larger than the default, since transcripts record the model ID
+// without the suffix.
+func ContextWindow(model string, contextTokens int) int {
+	if strings.HasSuffix(strings.ToLower(model), "[1m]") || contextTokens > DefaultContextWindow {
+		return ExtendedContextWindow
+	}
+	return DefaultContextWindow
+}
+
+// ContextPercent returns how full the session's context window is, from 0
+// to 100, or -1 when no assistant message has reported usage yet.
+func (s Session) ContextPercent() int {
+	if s.ContextTokens == 0 || s.ContextLimit == 0 {
+		return -1
+	}
+	return min(100, s.ContextTokens*100/s.ContextLimit)
+}
+
+// NearCompaction reports whether the session's context is close enough to
+// its limit that Claude Code will soon compact it.
+func (s Session) NearCompaction() bool {
+	return s.ContextPercent() >= NearCompactionPercent
+}
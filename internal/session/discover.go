@@ -7,10 +7,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/Jevs21/cctop/internal/git"
 )
 
 // psEntry holds raw data parsed from a single ps output line.
@@ -27,18 +30,125 @@ type ideLockFile struct {
 	WorkspaceFolders []string `json:"workspaceFolders"`
 	IDEName          string   `json:"ideName"`
 	Transport        string   `json:"transport"`
+
+	// Port the IDE's MCP server listens on, from the lock file's name
+	// (<port>.lock); 0 when the name is not a port
+	Port int `json:"-"`
+}
+
+// connection labels the IDE's MCP connection, e.g. "ws:37791". Claude Code
+// writes no transport for WebSocket servers.
+func (l ideLockFile) connection() string {
+	transport := l.Transport
+	if transport == "" {
+		transport = "ws"
+	}
+	if l.Port == 0 {
+		return transport
+	}
+	return fmt.Sprintf("%s:%d", transport, l.Port)
+}
+
+// DiscoverOptions controls which sessions discovery returns beyond the
+// running processes.
+type DiscoverOptions struct {
+	// RecentWindow, when positive, also lists transcripts modified within the
+	// window whose process has exited.
+	RecentWindow time.Duration
+
+	// ClaudeDirs are config directories searched in addition to the
+	// default one, for sessions started with CLAUDE_CONFIG_DIR.
+	ClaudeDirs []string
+
+	// AllUsers includes other users' claude processes, each read from its
+	// owner's config directory. Without it only the current user's
+	// processes are listed.
+	AllUsers bool
 }
 
-// DiscoverAll is the main orchestrator that finds all running Claude sessions.
-// It performs a single ps call, a single batched lsof call, discovers both CLI
-// and IDE sessions, deduplicates by CWD, and enriches with transcript metadata.
+// DiscoverAll finds all running Claude sessions with default options.
 func DiscoverAll() []Session {
-	claudeDir := filepath.Join(os.Getenv("HOME"), ".claude")
+	return Discover(DiscoverOptions{})
+}
+
+// Discover finds running Claude sessions and, when requested, recently
+// exited ones.
+func Discover(opts DiscoverOptions) []Session {
+	claudeDirs := ConfigDirs(opts.ClaudeDirs)
+	sessions := discoverLiveSessions(claudeDirs, opts.AllUsers)
+
+	if opts.RecentWindow > 0 {
+		for _, claudeDir := range claudeDirs {
+			recent := discoverRecentSessions(claudeDir, opts.RecentWindow, sessions, time.Now())
+			if claudeDir != claudeDirs[0] {
+				for i := range recent {
+					recent[i].ClaudeDir = claudeDir
+				}
+			}
+			sessions = append(sessions, recent...)
+		}
+	}
+
+	inspectRepos(sessions)
+	DetectConflicts(sessions, time.Now())
+
+	return sessions
+}
+
+// inspectRepos attaches the live git state of each session's working
+// directory. Sessions sharing a directory share one lookup. Sessions in a
+// linked worktree are renamed repo@worktree, since the last two path
+// components of a worktree (e.g. .worktrees/feat-a) do not name the project.
+func inspectRepos(sessions []Session) {
+	byCWD := make(map[string]*git.Info)
+	for i := range sessions {
+		cwd := sessions[i].CWD
+		if cwd == "" {
+			continue
+		}
+		info, seen := byCWD[cwd]
+		if !seen {
+			if found, ok := git.Inspect(cwd); ok {
+				info = &found
+			}
+			byCWD[cwd] = info
+		}
+		sessions[i].Git = info
+		if info != nil && info.Worktree != "" {
+			sessions[i].Project = WorktreeProjectName(info.Repository, info.Worktree, info.Root, cwd)
+		}
+	}
+}
+
+// WorktreeProjectName names a session running in a linked worktree:
+// repo@worktree, followed by the CWD's path below the worktree root.
+func WorktreeProjectName(repository, worktree, root, cwd string) string {
+	name := repository + "@" + worktree
+	rel, err := filepath.Rel(root, cwd)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return name
+	}
+	return name + "/" + filepath.ToSlash(rel)
+}
 
+// discoverLiveSessions is the main orchestrator that finds all running Claude
+// sessions. It performs a single ps call, a single batched lsof call, discovers
+// both CLI and IDE sessions, deduplicates by CWD, and enriches with transcript
+// metadata. claudeDirs lists the config directories to search, the default
+// first; allUsers keeps other users' processes.
+func discoverLiveSessions(claudeDirs []string, allUsers bool) []Session {
 	// Single ps call for all Claude processes
 	psOutput := runPS()
 	entries := ParsePS(psOutput)
 
+	uids := processUIDs(entries)
+	ideDirs := claudeDirs
+	if allUsers {
+		ideDirs = append(slices.Clone(claudeDirs), foreignConfigDirs(uids)...)
+	} else {
+		entries = ownEntries(entries, uids)
+	}
+
 	if len(entries) == 0 {
 		return nil
 	}
@@ -46,12 +156,39 @@ func DiscoverAll() []Session {
 	// Batch-resolve CWDs for all PIDs
 	cwdMap := BatchResolveCWDs(entries)
 
+	// Containerised processes see their own filesystem; resolve them first
+	// so host discovery does not misread their paths. They are not
+	// deduplicated against host sessions: a host claude in the same
+	// bind-mounted repository is a separate session.
+	entries, sessions := discoverContainerSessions(entries, cwdMap)
+
+	// Other users' processes are usually unreadable; list them anyway
+	if allUsers {
+		var hidden []Session
+		entries, hidden = discoverInaccessibleSessions(entries, cwdMap, uids)
+		sessions = append(sessions, hidden...)
+	}
+
 	// Track seen CWDs for deduplication (IDE wins over CLI)
 	seenCWDs := make(map[string]bool)
-	var sessions []Session
 
-	// Discover IDE sessions first (they have richer metadata from lock files)
-	ideSessions := discoverIDESessions(claudeDir, entries, cwdMap)
+	// Discover IDE sessions first (they have richer metadata from lock
+	// files); a process matched by lock files in several config
+	// directories is listed once
+	var ideSessions []Session
+	seenPIDs := make(map[int]bool)
+	for _, claudeDir := range ideDirs {
+		for _, found := range DiscoverIDESessions(claudeDir, entries, cwdMap) {
+			if seenPIDs[found.PID] {
+				continue
+			}
+			seenPIDs[found.PID] = true
+			if claudeDir != claudeDirs[0] {
+				found.ClaudeDir = claudeDir
+			}
+			ideSessions = append(ideSessions, found)
+		}
+	}
 	for i := range ideSessions {
 		seenCWDs[ideSessions[i].CWD] = true
 		sessions = append(sessions, ideSessions[i])
@@ -61,12 +198,81 @@ func DiscoverAll() []Session {
 	cliSessions := discoverCLISessions(entries, cwdMap, seenCWDs)
 	sessions = append(sessions, cliSessions...)
 
-	// Enrich all sessions with transcript metadata (topic, branch, state, messages)
-	EnrichSessions(sessions, claudeDir)
+	// Enrich all sessions with transcript metadata (topic, branch, state,
+	// messages) from each one's config directory
+	if allUsers {
+		assignOwners(sessions, uids)
+	}
+	assignConfigDirs(sessions, claudeDirs)
+	EnrichSessions(sessions, claudeDirs[0])
+	if allUsers {
+		markNoAccess(sessions)
+	}
 
 	return sessions
 }
 
+// discoverRecentSessions lists transcripts modified within window that do not
+// belong to a live session, as exited sessions without a PID.
+func discoverRecentSessions(claudeDir string, window time.Duration, live []Session, now time.Time) []Session {
+	matches, err := filepath.Glob(filepath.Join(claudeDir, "projects", "*", "*.jsonl"))
+	if err != nil {
+		return nil
+	}
+
+	liveTranscripts := make(map[string]bool, len(live))
+	for _, s := range live {
+		liveTranscripts[s.TranscriptPath] = true
+	}
+
+	var sessions []Session
+	for _, transcriptPath := range matches {
+		if liveTranscripts[transcriptPath] {
+			continue
+		}
+
+		info, statErr := os.Stat(transcriptPath)
+		if statErr != nil || now.Sub(info.ModTime()) > window {
+			continue
+		}
+
+		entry := cachedHistoryEntry(transcriptPath, info)
+
+		// Duration for exited sessions is how long the session ran
+		var duration time.Duration
+		if !entry.Created.IsZero() {
+			duration = entry.Modified.Sub(entry.Created)
+		}
+
+		recent := Session{
+			CWD:            entry.CWD,
+			State:          StateExited,
+			Source:         Source{Type: "-"},
+			Project:        entry.Project,
+			Topic:          entry.Topic,
+			Branch:         entry.Branch,
+			Duration:       duration,
+			Messages:       entry.Messages,
+			SessionID:      entry.SessionID,
+			TranscriptPath: entry.Path,
+			LastActivity:   entry.Modified,
+		}
+		applyTranscriptStats(entry.Path, info.Size(), &recent)
+		sessions = append(sessions, recent)
+	}
+
+	return sessions
+}
+
+// DefaultClaudeDir returns the Claude config directory of the current user:
+// CLAUDE_CONFIG_DIR when set, otherwise ~/.claude.
+func DefaultClaudeDir() string {
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(os.Getenv("HOME"), ".claude")
+}
+
 // runPS executes ps and returns raw stdout.
 func runPS() string {
 	out, err := exec.Command("ps", "-eo", "pid,etime,tty,command").CombinedOutput()
@@ -182,12 +388,7 @@ func discoverCLISessions(entries []psEntry, cwdMap map[int]string, seenCWDs map[
 		}
 
 		// Ensure this is a top-level claude command
-		commandParts := strings.Fields(entry.Command)
-		if len(commandParts) == 0 {
-			continue
-		}
-		baseName := filepath.Base(commandParts[0])
-		if baseName != "claude" {
+		if !isClaudeCommand(entry.Command) {
 			continue
 		}
 
@@ -203,30 +404,93 @@ func discoverCLISessions(entries []psEntry, cwdMap map[int]string, seenCWDs map[
 		seenCWDs[cwd] = true
 
 		duration := ParseEtime(entry.Etime)
+		model, permissionMode := ParseLaunchFlags(entry.Command)
 
 		sessions = append(sessions, Session{
-			PID:      entry.PID,
-			CWD:      cwd,
-			Source:   Source{Type: "CLI"},
-			Project:  ShortProjectName(cwd),
-			Duration: duration,
+			PID:            entry.PID,
+			TTY:            entry.TTY,
+			CWD:            cwd,
+			Source:         Source{Type: "CLI"},
+			Project:        ShortProjectName(cwd),
+			Duration:       duration,
+			Model:          model,
+			PermissionMode: permissionMode,
 		})
 	}
 
 	return sessions
 }
 
-// discoverIDESessions finds IDE-launched Claude sessions from lock files.
-func discoverIDESessions(claudeDir string, entries []psEntry, cwdMap map[int]string) []Session {
+// isClaudeCommand reports whether a ps command line runs the claude
+// executable, rather than merely mentioning it in its arguments.
+func isClaudeCommand(command string) bool {
+	commandParts := strings.Fields(command)
+	return len(commandParts) > 0 && filepath.Base(commandParts[0]) == "claude"
+}
+
+// DiscoverIDESessions finds IDE-launched Claude sessions from the lock files
+// in claudeDir/ide. Every claude process without a terminal whose CWD lies
+// within one of a window's workspace folders belongs to that window, so
+// multi-root workspaces and several sessions per window are all listed. A
+// CWD inside folders of several windows goes to the deepest folder.
+func DiscoverIDESessions(claudeDir string, entries []psEntry, cwdMap map[int]string) []Session {
 	var sessions []Session
 
-	ideDir := filepath.Join(claudeDir, "ide")
-	lockFiles, err := filepath.Glob(filepath.Join(ideDir, "*.lock"))
-	if err != nil || len(lockFiles) == 0 {
+	lockFiles := readIDELockFiles(filepath.Join(claudeDir, "ide"))
+	if len(lockFiles) == 0 {
 		return sessions
 	}
 
-	for _, lockFilePath := range lockFiles {
+	for _, entry := range entries {
+		// IDE-spawned processes have no controlling terminal: ?? on
+		// macOS, ? on Linux
+		if entry.TTY != "??" && entry.TTY != "?" {
+			continue
+		}
+
+		// Shells and tools the session spawns share its CWD and lack a
+		// terminal too; only the claude executable itself is a session
+		if !isClaudeCommand(entry.Command) {
+			continue
+		}
+
+		cwd, hasCWD := cwdMap[entry.PID]
+		if !hasCWD || cwd == "" {
+			continue
+		}
+
+		lockFile, found := matchIDELockFile(lockFiles, cwd)
+		if !found {
+			continue
+		}
+
+		duration := ParseEtime(entry.Etime)
+		model, permissionMode := ParseLaunchFlags(entry.Command)
+		sessions = append(sessions, Session{
+			PID:            entry.PID,
+			CWD:            cwd,
+			Source:         Source{Type: shortenIDEName(lockFile.IDEName)},
+			Project:        ShortProjectName(cwd),
+			Duration:       duration,
+			Model:          model,
+			PermissionMode: permissionMode,
+			IDEConnection:  lockFile.connection(),
+		})
+	}
+
+	return sessions
+}
+
+// readIDELockFiles reads the lock files in ideDir whose IDE is still
+// running, with workspace folders cleaned for path matching.
+func readIDELockFiles(ideDir string) []ideLockFile {
+	paths, err := filepath.Glob(filepath.Join(ideDir, "*.lock"))
+	if err != nil {
+		return nil
+	}
+
+	var lockFiles []ideLockFile
+	for _, lockFilePath := range paths {
 		data, readErr := os.ReadFile(lockFilePath)
 		if readErr != nil {
 			continue
@@ -246,35 +510,30 @@ func discoverIDESessions(claudeDir string, entries []psEntry, cwdMap map[int]str
 			continue
 		}
 
-		workspace := lockFile.WorkspaceFolders[0]
-		ideName := shortenIDEName(lockFile.IDEName)
-
-		// Find a claude process (TTY=??) whose CWD matches this workspace
-		for _, entry := range entries {
-			if entry.TTY != "??" {
-				continue
-			}
+		lockFile.Port, _ = strconv.Atoi(strings.TrimSuffix(filepath.Base(lockFilePath), ".lock"))
+		for i, folder := range lockFile.WorkspaceFolders {
+			lockFile.WorkspaceFolders[i] = filepath.Clean(folder)
+		}
+		lockFiles = append(lockFiles, lockFile)
+	}
 
-			cwd, hasCWD := cwdMap[entry.PID]
-			if !hasCWD {
-				continue
-			}
+	return lockFiles
+}
 
-			if strings.HasPrefix(cwd, workspace) {
-				duration := ParseEtime(entry.Etime)
-				sessions = append(sessions, Session{
-					PID:      entry.PID,
-					CWD:      workspace,
-					Source:   Source{Type: ideName},
-					Project:  ShortProjectName(workspace),
-					Duration: duration,
-				})
-				break // One Claude process per workspace
+// matchIDELockFile returns the lock file with the deepest workspace folder
+// containing cwd. Folders match on path boundaries: /a/app does not contain
+// /a/app2.
+func matchIDELockFile(lockFiles []ideLockFile, cwd string) (ideLockFile, bool) {
+	var best ideLockFile
+	bestLen := -1
+	for _, lockFile := range lockFiles {
+		for _, folder := range lockFile.WorkspaceFolders {
+			if pathWithin(cwd, folder) && len(folder) > bestLen {
+				best, bestLen = lockFile, len(folder)
 			}
 		}
 	}
-
-	return sessions
+	return best, bestLen >= 0
 }
 
 // isProcessAlive checks if a PID is running by sending signal 0.
@@ -287,20 +546,6 @@ func isProcessAlive(pid int) bool {
 	return err == nil
 }
 
-// shortenIDEName converts verbose IDE names to short display names.
-func shortenIDEName(fullName string) string {
-	if strings.Contains(fullName, "Visual Studio Code") {
-		return "VSCode"
-	}
-	if strings.Contains(fullName, "Cursor") {
-		return "Cursor"
-	}
-	if fullName == "" {
-		return "IDE"
-	}
-	return fullName
-}
-
 // ParseEtime parses ps etime format (DD-HH:MM:SS, HH:MM:SS, MM:SS, or SS) to a Duration.
 func ParseEtime(etime string) time.Duration {
 	etime = strings.TrimSpace(etime)
@@ -0,0 +1,161 @@
+package session
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ConflictWindow is how recent an edit must be to count towards a conflict.
+// Two long-running sessions that each touched a file hours apart are not
+// racing on it.
+const ConflictWindow = 30 * time.Minute
+
+// editTools are the tools whose tool_use blocks modify a file.
+var editTools = map[string]bool{
+	"Edit":         true,
+	"Write":        true,
+	"MultiEdit":    true,
+	"NotebookEdit": true,
+}
+
+// FileTouch records a session's edits to one file.
+type FileTouch struct {
+	Path        string    `json:"path"`         // Absolute path of the file
+	Edits       int       `json:"edits"`        // Number of edit tool calls
+	LastTouched time.Time `json:"last_touched"` // Timestamp of the latest edit
+}
+
+// Conflict is a file this session has edited that another live session has
+// also edited.
+type Conflict struct {
+	Path         string `json:"path"`
+	OtherPID     int    `json:"other_pid"`
+	OtherProject string `json:"other_project"`
+}
+
+// toolUseBlock is the subset of an assistant content block needed to find
+// edited files.
+type toolUseBlock struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Input struct {
+		FilePath     string `json:"file_path"`
+		NotebookPath string `json:"notebook_path"`
+	} `json:"input"`
+}
+
+// addEdits records the files modified by edit tool_use blocks in an
+// assistant message. Relative paths are resolved against the line's cwd.
+func (stats *transcriptStats) addEdits(entry jsonlLine) {
+	if len(entry.Message.Content) == 0 || entry.Message.Content[0] != '[' {
+		return
+	}
+
+	var blocks []toolUseBlock
+	if err := json.Unmarshal(entry.Message.Content, &blocks); err != nil {
+		return
+	}
+
+	timestamp, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+	for _, block := range blocks {
+		if block.Type != "tool_use" || !editTools[block.Name] {
+			continue
+		}
+
+		path := block.Input.FilePath
+		if path == "" {
+			path = block.Input.NotebookPath
+		}
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) && entry.CWD != "" {
+			path = filepath.Join(entry.CWD, path)
+		}
+		path = filepath.Clean(path)
+
+		if stats.files == nil {
+			stats.files = make(map[string]*FileTouch)
+		}
+		touch, ok := stats.files[path]
+		if !ok {
+			touch = &FileTouch{Path: path}
+			stats.files[path] = touch
+		}
+		touch.Edits++
+		if timestamp.After(touch.LastTouched) {
+			touch.LastTouched = timestamp
+		}
+	}
+}
+
+// filesTouched returns the edited files, most recently touched first.
+func (stats *transcriptStats) filesTouched() []FileTouch {
+	if len(stats.files) == 0 {
+		return nil
+	}
+
+	files := make([]FileTouch, 0, len(stats.files))
+	for _, touch := range stats.files {
+		files = append(files, *touch)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].LastTouched.Equal(files[j].LastTouched) {
+			return files[i].LastTouched.After(files[j].LastTouched)
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// DetectConflicts records, on each live session, the files it shares with
+// another live session, counting only edits within ConflictWindow of now.
+// Paths are absolute, so the same relative file in two worktrees of one
+// repository is not a conflict.
+func DetectConflicts(sessions []Session, now time.Time) {
+	editors := make(map[string][]int) // File path → indexes of live sessions editing it
+	for i, s := range sessions {
+		if s.State == StateExited {
+			continue
+		}
+		for _, touch := range s.Files {
+			if now.Sub(touch.LastTouched) > ConflictWindow {
+				continue
+			}
+			editors[touch.Path] = append(editors[touch.Path], i)
+		}
+	}
+
+	for i := range sessions {
+		sessions[i].Conflicts = nil
+	}
+	for path, indexes := range editors {
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, i := range indexes {
+			for _, j := range indexes {
+				if i == j {
+					continue
+				}
+				sessions[i].Conflicts = append(sessions[i].Conflicts, Conflict{
+					Path:         path,
+					OtherPID:     sessions[j].PID,
+					OtherProject: sessions[j].Project,
+				})
+			}
+		}
+	}
+
+	for i := range sessions {
+		conflicts := sessions[i].Conflicts
+		sort.Slice(conflicts, func(a, b int) bool {
+			if conflicts[a].Path != conflicts[b].Path {
+				return conflicts[a].Path < conflicts[b].Path
+			}
+			return conflicts[a].OtherPID < conflicts[b].OtherPID
+		})
+	}
+}
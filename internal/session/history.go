@@ -0,0 +1,319 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HistoryEntry describes a transcript on disk, whether or not its session is
+// still running.
+type HistoryEntry struct {
+	SessionID string
+	Path      string    // Absolute path to the JSONL transcript
+	CWD       string    // Working directory recorded in the transcript
+	Project   string    // Last 2 path components of CWD
+	Topic     string    // Cleaned first user prompt
+	Branch    string    // Git branch from the last transcript line
+	Messages  int       // Message count from the index, or approximate line count
+	Size      int64     // Transcript size in bytes
+	Created   time.Time // Timestamp of the first transcript line, if recorded
+	Modified  time.Time // Transcript mtime
+}
+
+// historyCache stores parsed transcripts across scans. The history browser
+// scans in the background while refreshes in recent mode read it too.
+// Key: "path:mtime"
+var (
+	historyCacheMu sync.Mutex
+	historyCache   = make(map[string]HistoryEntry)
+)
+
+// ResumeCommand returns the shell command that reopens this session.
+func (h HistoryEntry) ResumeCommand() string {
+	return ResumeCommand(h.CWD, h.SessionID)
+}
+
+// ScanHistory lists every transcript under <claudeDir>/projects of each of
+// claudeDirs, newest first. Metadata comes from each project's
+// sessions-index.json when it lists the transcript, and from parsing the
+// transcript otherwise.
+func ScanHistory(claudeDirs ...string) []HistoryEntry {
+	var entries []HistoryEntry
+	for _, claudeDir := range claudeDirs {
+		projectDirs, err := filepath.Glob(filepath.Join(claudeDir, "projects", "*"))
+		if err != nil {
+			continue
+		}
+		for _, projectDir := range projectDirs {
+			entries = append(entries, scanProjectHistory(projectDir)...)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Modified.After(entries[j].Modified)
+	})
+	return entries
+}
+
+// scanProjectHistory lists the transcripts of a single project directory.
+func scanProjectHistory(projectDir string) []HistoryEntry {
+	matches, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+
+	index, hasIndex := loadSessionsIndex(filepath.Join(projectDir, "sessions-index.json"))
+
+	var entries []HistoryEntry
+	for _, transcriptPath := range matches {
+		info, statErr := os.Stat(transcriptPath)
+		if statErr != nil {
+			continue
+		}
+
+		if hasIndex {
+			if indexEntry, found := findIndexEntry(index, transcriptPath); found {
+				entries = append(entries, historyEntryFromIndex(index, indexEntry, transcriptPath, info))
+				continue
+			}
+		}
+		entries = append(entries, cachedHistoryEntry(transcriptPath, info))
+	}
+
+	return entries
+}
+
+// loadSessionsIndex reads and parses a sessions-index.json file.
+func loadSessionsIndex(indexPath string) (sessionsIndex, bool) {
+	var index sessionsIndex
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return index, false
+	}
+	if jsonErr := json.Unmarshal(data, &index); jsonErr != nil {
+		return index, false
+	}
+	return index, true
+}
+
+// findIndexEntry returns the index entry for a transcript, matched by full
+// path or by session ID.
+func findIndexEntry(index sessionsIndex, transcriptPath string) (sessionsIndexEntry, bool) {
+	sessionID := SessionIDFromPath(transcriptPath)
+	for _, entry := range index.Entries {
+		if entry.FullPath == transcriptPath || entry.SessionID == sessionID {
+			return entry, true
+		}
+	}
+	return sessionsIndexEntry{}, false
+}
+
+// historyEntryFromIndex builds a HistoryEntry from a sessions-index.json entry.
+// The working directory is read from the transcript head when the index does
+// not record it.
+func historyEntryFromIndex(index sessionsIndex, indexEntry sessionsIndexEntry, transcriptPath string, info os.FileInfo) HistoryEntry {
+	entry := HistoryEntry{
+		SessionID: SessionIDFromPath(transcriptPath),
+		Path:      transcriptPath,
+		CWD:       indexEntry.ProjectPath,
+		Topic:     CleanTopic(indexEntry.FirstPrompt),
+		Branch:    indexEntry.GitBranch,
+		Messages:  indexEntry.MessageCount,
+		Size:      info.Size(),
+		Modified:  info.ModTime(),
+	}
+
+	if created, err := time.Parse(time.RFC3339Nano, indexEntry.Created); err == nil {
+		entry.Created = created
+	}
+
+	if entry.CWD == "" {
+		entry.CWD = index.OriginalPath
+	}
+	if entry.CWD == "" || entry.Created.IsZero() {
+		headCWD, headCreated := readTranscriptHead(transcriptPath)
+		if entry.CWD == "" {
+			entry.CWD = headCWD
+		}
+		if entry.Created.IsZero() {
+			entry.Created = headCreated
+		}
+	}
+
+	if entry.CWD != "" {
+		entry.Project = ShortProjectName(entry.CWD)
+	}
+	if entry.Topic == "" && len(entry.SessionID) >= 8 {
+		entry.Topic = entry.SessionID[:8]
+	}
+
+	return entry
+}
+
+// cachedHistoryEntry returns the parsed transcript, reusing the previous parse
+// while the file's mtime is unchanged.
+func cachedHistoryEntry(transcriptPath string, info os.FileInfo) HistoryEntry {
+	cacheKey := transcriptPath + ":" + info.ModTime().Format(time.RFC3339Nano)
+	historyCacheMu.Lock()
+	cached, ok := historyCache[cacheKey]
+	historyCacheMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	entry := readHistoryEntry(transcriptPath, info)
+	historyCacheMu.Lock()
+	historyCache[cacheKey] = entry
+	historyCacheMu.Unlock()
+	return entry
+}
+
+// readHistoryEntry builds a HistoryEntry from the head and tail of a transcript.
+func readHistoryEntry(transcriptPath string, info os.FileInfo) HistoryEntry {
+	cwd, created := readTranscriptHead(transcriptPath)
+	entry := HistoryEntry{
+		SessionID: SessionIDFromPath(transcriptPath),
+		Path:      transcriptPath,
+		CWD:       cwd,
+		Topic:     CleanTopic(extractFirstPrompt(transcriptPath)),
+		Messages:  countLines(transcriptPath),
+		Size:      info.Size(),
+		Created:   created,
+		Modified:  info.ModTime(),
+	}
+
+	lastLine := ReadLastLine(transcriptPath)
+	if lastLine != "" {
+		var lastEntry jsonlLine
+		if jsonErr := json.Unmarshal([]byte(lastLine), &lastEntry); jsonErr == nil {
+			entry.Branch = lastEntry.GitBranch
+			if entry.CWD == "" {
+				entry.CWD = lastEntry.CWD
+			}
+			if entry.Topic == "" {
+				entry.Topic = lastEntry.Slug
+			}
+		}
+	}
+
+	if entry.CWD != "" {
+		entry.Project = ShortProjectName(entry.CWD)
+	}
+	if entry.Topic == "" && len(entry.SessionID) >= 8 {
+		entry.Topic = entry.SessionID[:8]
+	}
+
+	return entry
+}
+
+// readTranscriptHead returns the first cwd and first timestamp recorded in
+// the head of a transcript.
+func readTranscriptHead(jsonlPath string) (cwd string, created time.Time) {
+	file, err := os.Open(jsonlPath)
+	if err != nil {
+		return "", time.Time{}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	configureScannerBuffer(scanner)
+	lineCount := 0
+
+	for scanner.Scan() && lineCount < maxLinesToScanPrompt {
+		lineCount++
+		var entry jsonlLine
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &entry); jsonErr != nil {
+			continue
+		}
+		if created.IsZero() && entry.Timestamp != "" {
+			if parsed, parseErr := time.Parse(time.RFC3339Nano, entry.Timestamp); parseErr == nil {
+				created = parsed
+			}
+		}
+		if cwd == "" {
+			cwd = entry.CWD
+		}
+		if cwd != "" && !created.IsZero() {
+			break
+		}
+	}
+
+	return cwd, created
+}
+
+// MatchHistory fuzzy-matches entries against a query by project, topic, path
+// and branch. Every whitespace-separated query term must match, either as a
+// substring of any field (scored higher) or as an in-order subsequence of the
+// project name. Results are ordered by score, then by recency.
+func MatchHistory(entries []HistoryEntry, query string) []HistoryEntry {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return entries
+	}
+
+	type scoredEntry struct {
+		entry HistoryEntry
+		score int
+	}
+	var scored []scoredEntry
+
+	for _, entry := range entries {
+		haystack := strings.ToLower(strings.Join([]string{entry.Project, entry.Topic, entry.CWD, entry.Branch, entry.SessionID}, " "))
+		project := strings.ToLower(entry.Project)
+		total := 0
+		for _, term := range terms {
+			termScore := fuzzyScore(haystack, project, term)
+			if termScore == 0 {
+				total = 0
+				break
+			}
+			total += termScore
+		}
+		if total > 0 {
+			scored = append(scored, scoredEntry{entry: entry, score: total})
+		}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].entry.Modified.After(scored[j].entry.Modified)
+	})
+
+	matched := make([]HistoryEntry, len(scored))
+	for i, s := range scored {
+		matched[i] = s.entry
+	}
+	return matched
+}
+
+// fuzzyScore scores how well term matches: 3 for a substring of haystack at a
+// word start, 2 for any substring, 1 for an in-order subsequence of project,
+// 0 otherwise.
+func fuzzyScore(haystack string, project string, term string) int {
+	if idx := strings.Index(haystack, term); idx != -1 {
+		atWordStart := idx == 0 || strings.ContainsRune(" /-_.", rune(haystack[idx-1]))
+		if atWordStart {
+			return 3
+		}
+		return 2
+	}
+
+	pos := 0
+	for _, r := range term {
+		next := strings.IndexRune(project[pos:], r)
+		if next == -1 {
+			return 0
+		}
+		pos += next + len(string(r))
+	}
+	return 1
+}
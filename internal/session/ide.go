@@ -0,0 +1,121 @@
+package session
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxIDENameLength is the width of the SRC column; derived names are cut to
+// fit it.
+const maxIDENameLength = 7
+
+// IDE describes how sessions from one IDE integration are displayed.
+type IDE struct {
+	Match string // Case-insensitive leading words of the lock file's ideName
+	Name  string // Short name used as the session's source
+	Icon  string // Glyph shown before the name; may be empty
+	Color string // Lipgloss colour for the name: an ANSI 256 number or #rrggbb; empty for the default
+}
+
+// builtinIDEs lists the known integrations. Earlier entries win.
+var builtinIDEs = []IDE{
+	{Match: "VSCodium", Name: "Codium", Icon: "◇", Color: "75"},
+	{Match: "Visual Studio Code", Name: "VSCode", Icon: "◆", Color: "33"},
+	{Match: "Cursor", Name: "Cursor", Icon: "▲", Color: "252"},
+	{Match: "Windsurf", Name: "Windsrf", Icon: "≋", Color: "43"},
+	{Match: "Zed", Name: "Zed", Icon: "◈", Color: "39"},
+	{Match: "Neovim", Name: "Neovim", Icon: "✦", Color: "71"},
+	{Match: "nvim", Name: "Neovim", Icon: "✦", Color: "71"},
+	{Match: "Vim", Name: "Vim", Icon: "✦", Color: "28"},
+
+	// JetBrains IDEs share one plugin and one colour
+	{Match: "IntelliJ", Name: "IDEA", Icon: "■", Color: "205"},
+	{Match: "PyCharm", Name: "PyCharm", Icon: "■", Color: "205"},
+	{Match: "GoLand", Name: "GoLand", Icon: "■", Color: "205"},
+	{Match: "WebStorm", Name: "WebStm", Icon: "■", Color: "205"},
+	{Match: "PhpStorm", Name: "PhpStm", Icon: "■", Color: "205"},
+	{Match: "RubyMine", Name: "RubyMn", Icon: "■", Color: "205"},
+	{Match: "CLion", Name: "CLion", Icon: "■", Color: "205"},
+	{Match: "RustRover", Name: "RustRvr", Icon: "■", Color: "205"},
+	{Match: "Rider", Name: "Rider", Icon: "■", Color: "205"},
+	{Match: "DataGrip", Name: "DataGrp", Icon: "■", Color: "205"},
+	{Match: "Android Studio", Name: "AStudio", Icon: "■", Color: "205"},
+	{Match: "JetBrains", Name: "JB", Icon: "■", Color: "205"},
+}
+
+// customIDEs holds mappings from the config file, consulted before the
+// built-in ones.
+var (
+	customIDEs   []IDE
+	customIDEsMu sync.RWMutex
+)
+
+// RegisterIDEs installs config-defined mappings. They take precedence over
+// the built-in registry, so they can both name unknown IDEs and restyle
+// known ones.
+func RegisterIDEs(ides []IDE) {
+	customIDEsMu.Lock()
+	defer customIDEsMu.Unlock()
+	customIDEs = append([]IDE(nil), ides...)
+}
+
+// registeredIDEs returns the config-defined mappings followed by the
+// built-in ones.
+func registeredIDEs() []IDE {
+	customIDEsMu.RLock()
+	defer customIDEsMu.RUnlock()
+	return append(append([]IDE(nil), customIDEs...), builtinIDEs...)
+}
+
+// LookupIDE returns the registry entry whose Match is the whole of a lock
+// file's ideName or its leading words, so "Visual Studio Code - Insiders"
+// is VS Code but "Zedd" is not Zed.
+func LookupIDE(ideName string) (IDE, bool) {
+	for _, ide := range registeredIDEs() {
+		if ide.Match != "" && matchesIDEName(ideName, ide.Match) {
+			return ide, true
+		}
+	}
+	return IDE{}, false
+}
+
+// matchesIDEName reports whether ideName starts with match, ignoring case,
+// and match ends on a word boundary.
+func matchesIDEName(ideName, match string) bool {
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(ideName)), strings.ToLower(match))
+	if !ok {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLetter(next) && !unicode.IsDigit(next)
+}
+
+// IDEByName returns the registry entry for a session source, as set from
+// the entry's Name.
+func IDEByName(name string) (IDE, bool) {
+	for _, ide := range registeredIDEs() {
+		if ide.Name == name {
+			return ide, true
+		}
+	}
+	return IDE{}, false
+}
+
+// shortenIDEName converts verbose IDE names to short display names. Names
+// missing from the registry keep their first word, cut to the SRC width.
+func shortenIDEName(fullName string) string {
+	if ide, ok := LookupIDE(fullName); ok {
+		return ide.Name
+	}
+	fields := strings.Fields(fullName)
+	if len(fields) == 0 {
+		return "IDE"
+	}
+	name := fields[0]
+	if utf8.RuneCountInString(name) > maxIDENameLength {
+		name = string([]rune(name)[:maxIDENameLength])
+	}
+	return name
+}
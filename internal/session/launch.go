@@ -0,0 +1,64 @@
+package session
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PermissionBypass is the permission mode in which tools run without asking.
+const PermissionBypass = "bypassPermissions"
+
+// ParseLaunchFlags extracts the model and permission mode from a claude
+// command line. Both --flag value and --flag=value forms are accepted;
+// --dangerously-skip-permissions implies bypassPermissions. Values that are
+// not set on the command line are empty.
+func ParseLaunchFlags(command string) (model string, permissionMode string) {
+	args := strings.Fields(command)
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			value = args[i+1]
+		}
+
+		switch name {
+		case "--model":
+			model = value
+		case "--permission-mode":
+			permissionMode = value
+		case "--dangerously-skip-permissions":
+			permissionMode = PermissionBypass
+		}
+	}
+	return model, permissionMode
+}
+
+// ShortModelName abbreviates a model ID for display by dropping the claude-
+// prefix and date suffix: claude-sonnet-4-5-20250929 becomes sonnet-4.5.
+func ShortModelName(model string) string {
+	name := strings.TrimPrefix(model, "claude-")
+
+	if index := strings.LastIndexByte(name, '-'); index != -1 && len(name)-index-1 == 8 && isDigits(name[index+1:]) {
+		name = name[:index]
+	}
+
+	// A trailing major-minor version reads better dotted
+	if n := len(name); n >= 4 && name[n-2] == '-' && isDigits(name[n-1:]) {
+		if major := strings.LastIndexByte(name[:n-2], '-'); major != -1 && isDigits(name[major+1:n-2]) {
+			name = name[:n-2] + "." + name[n-1:]
+		}
+	}
+	return name
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,7 +34,7 @@ const (
 	activeUserPromptThreshold = 5 * time.Minute
 )
 
-// cachedMetadata stores transcript metadata keyed by cwd + mtime.
+// cachedMetadata stores transcript metadata keyed by path + mtime.
 type cachedMetadata struct {
 	FullPath string
 	Topic    string
@@ -42,8 +43,11 @@ type cachedMetadata struct {
 }
 
 // metadataCache persists across refresh cycles.
-// Key: "cwd:mtime_unix"
-var metadataCache = make(map[string]cachedMetadata)
+// Key: "transcript_path:mtime"
+var (
+	metadataCacheMu sync.Mutex
+	metadataCache   = make(map[string]cachedMetadata)
+)
 
 // sessionsIndexEntry represents one entry in sessions-index.json.
 type sessionsIndexEntry struct {
@@ -55,24 +59,34 @@ type sessionsIndexEntry struct {
 	Created      string `json:"created"`
 	Modified     string `json:"modified"`
 	GitBranch    string `json:"gitBranch"`
+	ProjectPath  string `json:"projectPath"`
 }
 
 // sessionsIndex represents the sessions-index.json file.
 type sessionsIndex struct {
-	Entries []sessionsIndexEntry `json:"entries"`
+	Entries      []sessionsIndexEntry `json:"entries"`
+	OriginalPath string               `json:"originalPath"`
 }
 
 // jsonlLine represents the relevant fields from a JSONL transcript line.
 type jsonlLine struct {
 	Type    string `json:"type"`
 	Message struct {
+		ID      string          `json:"id"`
 		Role    string          `json:"role"`
+		Model   string          `json:"model"`
 		Content json.RawMessage `json:"content"`
+		Usage   *Usage          `json:"usage"`
 	} `json:"message"`
-	Slug      string `json:"slug"`
-	GitBranch string `json:"gitBranch"`
-	SessionID string `json:"sessionId"`
-	CWD       string `json:"cwd"`
+	Slug             string `json:"slug"`
+	Subtype          string `json:"subtype"`
+	IsSidechain      bool   `json:"isSidechain"`
+	IsCompactSummary bool   `json:"isCompactSummary"`
+	PermissionMode   string `json:"permissionMode"`
+	GitBranch        string `json:"gitBranch"`
+	SessionID        string `json:"sessionId"`
+	CWD              string `json:"cwd"`
+	Timestamp        string `json:"timestamp"`
 }
 
 // EnrichSessions adds state, topic, branch, and message count to each session
@@ -82,8 +96,23 @@ func EnrichSessions(sessions []Session, claudeDir string) {
 	now := time.Now()
 
 	for i := range sessions {
-		cwd := sessions[i].CWD
-		enrichSession(&sessions[i], projectsDir, cwd, now)
+		// Another user's session without a readable working directory has
+		// no project to look up
+		if sessions[i].NoAccess {
+			sessions[i].State = StateIdle
+			continue
+		}
+
+		// Container sessions keep transcripts under the container's own
+		// config directory, keyed by the path inside the container
+		dir, cwd := projectsDir, sessions[i].CWD
+		if sessions[i].ClaudeDir != "" {
+			dir = filepath.Join(sessions[i].ClaudeDir, "projects")
+		}
+		if sessions[i].ContainerCWD != "" {
+			cwd = sessions[i].ContainerCWD
+		}
+		enrichSession(&sessions[i], dir, cwd, now)
 	}
 }
 
@@ -98,6 +127,14 @@ func enrichSession(session *Session, projectsDir string, cwd string, now time.Ti
 	indexPath := filepath.Join(projectDir, "sessions-index.json")
 	fullPath, firstPrompt, messageCount, gitBranch, found := findSessionFromIndex(indexPath)
 
+	// The index records absolute paths as its writer saw them, which for a
+	// container session are paths inside the container; the transcript sits
+	// beside the index
+	if found && !fileExists(fullPath) {
+		fullPath = filepath.Join(projectDir, filepath.Base(fullPath))
+		found = fileExists(fullPath)
+	}
+
 	// Fallback: find newest JSONL file
 	if !found {
 		fullPath, firstPrompt, messageCount, gitBranch, found = findSessionFallback(projectDir)
@@ -113,9 +150,16 @@ func enrichSession(session *Session, projectsDir string, cwd string, now time.Ti
 		return
 	}
 	mtime := fileInfo.ModTime()
-	cacheKey := cwd + ":" + mtime.Format(time.RFC3339Nano)
-
-	if cached, ok := metadataCache[cacheKey]; ok {
+	cacheKey := fullPath + ":" + mtime.Format(time.RFC3339Nano)
+	session.SessionID = SessionIDFromPath(fullPath)
+	session.TranscriptPath = fullPath
+	session.LastActivity = mtime
+	applyTranscriptStats(fullPath, fileInfo.Size(), session)
+
+	metadataCacheMu.Lock()
+	cached, ok := metadataCache[cacheKey]
+	metadataCacheMu.Unlock()
+	if ok {
 		// Cache hit — reuse topic, messages, branch; always recompute state
 		session.Topic = cached.Topic
 		session.Messages = cached.Messages
@@ -148,27 +192,25 @@ func enrichSession(session *Session, projectsDir string, cwd string, now time.Ti
 	session.State = DetectState(fullPath, mtime, now)
 
 	// Store in cache
+	metadataCacheMu.Lock()
 	metadataCache[cacheKey] = cachedMetadata{
 		FullPath: fullPath,
 		Topic:    topic,
 		Messages: messageCount,
 		Branch:   gitBranch,
 	}
+	metadataCacheMu.Unlock()
+}
+
+// SessionIDFromPath returns the session UUID encoded in a transcript filename.
+func SessionIDFromPath(transcriptPath string) string {
+	return strings.TrimSuffix(filepath.Base(transcriptPath), ".jsonl")
 }
 
 // findSessionFromIndex reads sessions-index.json and returns the most recent session.
 func findSessionFromIndex(indexPath string) (fullPath string, firstPrompt string, messageCount int, gitBranch string, found bool) {
-	data, err := os.ReadFile(indexPath)
-	if err != nil {
-		return "", "", 0, "", false
-	}
-
-	var index sessionsIndex
-	if jsonErr := json.Unmarshal(data, &index); jsonErr != nil {
-		return "", "", 0, "", false
-	}
-
-	if len(index.Entries) == 0 {
+	index, ok := loadSessionsIndex(indexPath)
+	if !ok || len(index.Entries) == 0 {
 		return "", "", 0, "", false
 	}
 
@@ -0,0 +1,206 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// NoAccessTopic is shown in place of the topic of another user's session
+// whose transcripts cannot be read.
+const NoAccessTopic = "no access"
+
+// owner is the user account a process runs as.
+type owner struct {
+	Name string // Login name, or the numeric UID when it has no passwd entry
+	Home string // Home directory; empty when unknown
+}
+
+// owners caches account lookups by UID; accounts rarely change while cctop
+// runs, and discovery may run from several goroutines.
+var (
+	owners   = make(map[int]owner)
+	ownersMu sync.Mutex
+)
+
+// lookupOwner returns the account with the given UID.
+func lookupOwner(uid int) owner {
+	ownersMu.Lock()
+	defer ownersMu.Unlock()
+
+	if o, ok := owners[uid]; ok {
+		return o
+	}
+	o := owner{Name: strconv.Itoa(uid)}
+	if account, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		o = owner{Name: account.Username, Home: account.HomeDir}
+	}
+	owners[uid] = o
+	return o
+}
+
+// processUIDs returns the UID each entry's process runs as. On Linux the
+// owner of /proc/<pid> is the process's real UID; elsewhere one ps call
+// covers all PIDs. PIDs whose owner cannot be read are absent.
+func processUIDs(entries []psEntry) map[int]int {
+	uids := make(map[int]int)
+	if len(entries) == 0 {
+		return uids
+	}
+
+	if runtime.GOOS == "linux" {
+		for _, entry := range entries {
+			info, err := os.Stat(fmt.Sprintf("/proc/%d", entry.PID))
+			if err != nil {
+				continue
+			}
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+				uids[entry.PID] = int(stat.Uid)
+			}
+		}
+		return uids
+	}
+
+	pidStrs := make([]string, len(entries))
+	for i, entry := range entries {
+		pidStrs[i] = strconv.Itoa(entry.PID)
+	}
+	out, err := exec.Command("ps", "-o", "pid=,uid=", "-p", strings.Join(pidStrs, ",")).Output()
+	if err != nil {
+		return uids
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, pidErr := strconv.Atoi(fields[0])
+		uid, uidErr := strconv.Atoi(fields[1])
+		if pidErr == nil && uidErr == nil {
+			uids[pid] = uid
+		}
+	}
+	return uids
+}
+
+// ownEntries drops processes that belong to other users. Processes whose
+// owner is unknown are kept, matching discovery before owners were checked.
+func ownEntries(entries []psEntry, uids map[int]int) []psEntry {
+	self := os.Getuid()
+	var own []psEntry
+	for _, entry := range entries {
+		if uid, known := uids[entry.PID]; known && uid != self {
+			continue
+		}
+		own = append(own, entry)
+	}
+	return own
+}
+
+// foreignConfigDirs returns the default config directory of every other
+// user running claude, so their IDE lock files are found too.
+func foreignConfigDirs(uids map[int]int) []string {
+	self := os.Getuid()
+	seen := make(map[int]bool)
+	var dirs []string
+	for _, uid := range uids {
+		if uid == self || seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		if home := lookupOwner(uid).Home; home != "" {
+			dirs = append(dirs, filepath.Join(home, ".claude"))
+		}
+	}
+	return dirs
+}
+
+// discoverInaccessibleSessions lists other users' CLI processes whose working
+// directory cannot be read, which is all of them without root. They are
+// returned as sessions without a CWD so --all-users still shows who is
+// running claude; the remaining entries go through normal discovery.
+func discoverInaccessibleSessions(entries []psEntry, cwdMap map[int]string, uids map[int]int) ([]psEntry, []Session) {
+	self := os.Getuid()
+	var rest []psEntry
+	var sessions []Session
+	for _, entry := range entries {
+		uid, known := uids[entry.PID]
+		commandParts := strings.Fields(entry.Command)
+		if !known || uid == self || cwdMap[entry.PID] != "" || entry.TTY == "??" ||
+			len(commandParts) == 0 || filepath.Base(commandParts[0]) != "claude" {
+			rest = append(rest, entry)
+			continue
+		}
+
+		model, permissionMode := ParseLaunchFlags(entry.Command)
+		sessions = append(sessions, Session{
+			PID:            entry.PID,
+			TTY:            entry.TTY,
+			Source:         Source{Type: "CLI"},
+			Project:        "-",
+			Duration:       ParseEtime(entry.Etime),
+			Model:          model,
+			PermissionMode: permissionMode,
+			NoAccess:       true,
+		})
+	}
+	return rest, sessions
+}
+
+// assignOwners records the account of every live session and points other
+// users' sessions at their own config directory: the process's
+// CLAUDE_CONFIG_DIR when readable, else ~/.claude under the owner's home.
+// Sessions whose directory was already found (containers, IDE lock files)
+// keep it.
+func assignOwners(sessions []Session, uids map[int]int) {
+	self := os.Getuid()
+	for i := range sessions {
+		s := &sessions[i]
+		uid, known := uids[s.PID]
+		if !known {
+			continue
+		}
+		o := lookupOwner(uid)
+		s.User = o.Name
+		if uid == self || s.ClaudeDir != "" {
+			continue
+		}
+		s.ClaudeDir = processConfigDir(s.PID)
+		if s.ClaudeDir == "" && o.Home != "" {
+			s.ClaudeDir = filepath.Join(o.Home, ".claude")
+		}
+		if s.ClaudeDir == "" {
+			s.NoAccess = true
+		}
+	}
+}
+
+// markNoAccess flags other users' sessions whose transcripts could not be
+// matched because their project directory is unreadable, so they show
+// "no access" rather than looking idle with no topic.
+func markNoAccess(sessions []Session) {
+	self := lookupOwner(os.Getuid()).Name
+	for i := range sessions {
+		s := &sessions[i]
+		if s.User == "" || s.User == self || s.NoAccess || s.TranscriptPath != "" || s.ClaudeDir == "" {
+			continue
+		}
+		cwd := s.CWD
+		if s.ContainerCWD != "" {
+			cwd = s.ContainerCWD
+		}
+		_, err := os.ReadDir(filepath.Join(s.ClaudeDir, "projects", EncodePath(cwd)))
+		if errors.Is(err, fs.ErrPermission) {
+			s.NoAccess = true
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package session
+
+import "github.com/Jevs21/cctop/internal/process"
+
+// AttachResources sets each live local session's CPU and memory use from a
+// process table sample: its claude process plus every descendant (shells,
+// test runners, dev servers, MCP servers). Remote sessions keep the values
+// their host reported; exited ones have none.
+func AttachResources(sessions []Session, table process.Table) {
+	for i := range sessions {
+		s := &sessions[i]
+		if s.PID <= 0 || s.Host != "" {
+			continue
+		}
+		root, ok := table.Get(s.PID)
+		if !ok {
+			continue
+		}
+		s.CPUPercent, s.Memory, s.Processes = root.CPUPercent, root.RSS, 1
+		for _, p := range table.Descendants(s.PID) {
+			s.CPUPercent += p.CPUPercent
+			s.Memory += p.RSS
+			s.Processes++
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package session
+
+import "strings"
+
+// ResumeCommand returns a shell command that reopens the session in its
+// original working directory, or an empty string if the session ID is unknown.
+func (s Session) ResumeCommand() string {
+	command := ResumeCommand(s.CWD, s.SessionID)
+	if s.Container != "" && command != "" {
+		// The transcript lives inside the container, so resume there
+		command = ContainerResumeCommand(s.ContainerRuntime, s.Container, s.ContainerCWD, s.SessionID)
+	}
+	if s.Host != "" && command != "" {
+		// Resume on the session's own machine, with a terminal for the TUI
+		return "ssh -t " + ShellQuote(s.Host) + " " + ShellQuote(command)
+	}
+	return command
+}
+
+// ResumeCommand builds `cd <cwd> && claude --resume <id>` with shell quoting.
+func ResumeCommand(cwd string, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	if cwd == "" {
+		return "claude --resume " + ShellQuote(sessionID)
+	}
+	return "cd " + ShellQuote(cwd) + " && claude --resume " + ShellQuote(sessionID)
+}
+
+// ContainerResumeCommand builds `<runtime> exec -it -w <cwd> <container>
+// claude --resume <id>` with shell quoting. The runtime defaults to docker.
+func ContainerResumeCommand(runtime string, container string, cwd string, sessionID string) string {
+	if runtime == "" {
+		runtime = "docker"
+	}
+	command := ShellQuote(runtime) + " exec -it"
+	if cwd != "" {
+		command += " -w " + ShellQuote(cwd)
+	}
+	return command + " " + ShellQuote(container) + " claude --resume " + ShellQuote(sessionID)
+}
+
+// ShellQuote quotes a string for POSIX shells. Strings made only of safe
+// characters are returned unchanged.
+func ShellQuote(value string) string {
+	if value == "" {
+		return "''"
+	}
+
+	safe := true
+	for _, r := range value {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum && !strings.ContainsRune("-_./:@%+=,", r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
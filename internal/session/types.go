@@ -3,6 +3,8 @@ package session
 import (
 	"fmt"
 	"time"
+
+	"github.com/Jevs21/cctop/internal/git"
 )
 
 // State represents a session's current activity state.
@@ -13,6 +15,7 @@ const (
 	StateWaiting              // Claude has responded, awaiting user input
 	StateInput                // Claude is blocked waiting for user response to a question
 	StateIdle                 // Session exists but has been inactive
+	StateExited               // Process has exited; transcript was recently modified
 )
 
 // String returns the human-readable name for a State.
@@ -26,11 +29,30 @@ func (s State) String() string {
 		return "input"
 	case StateIdle:
 		return "idle"
+	case StateExited:
+		return "exited"
 	default:
 		return "unknown"
 	}
 }
 
+// MarshalText encodes a State as its name, so JSON output reads "waiting"
+// rather than an enum value.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a State from its name.
+func (s *State) UnmarshalText(text []byte) error {
+	for candidate := StateActive; candidate <= StateExited; candidate++ {
+		if candidate.String() == string(text) {
+			*s = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown session state %q", text)
+}
+
 // Priority returns the sort priority for a State (lower = higher priority).
 func (s State) Priority() int {
 	return int(s)
@@ -38,7 +60,7 @@ func (s State) Priority() int {
 
 // Source represents how a Claude session was launched.
 type Source struct {
-	Type string // "CLI", "VSCode", "Cursor", or other IDE name
+	Type string `json:"type"` // "CLI", "VSCode", "Cursor", or other IDE name
 }
 
 // String returns the display name of the source.
@@ -48,15 +70,73 @@ func (s Source) String() string {
 
 // Session holds all discoverable metadata for a single Claude Code session.
 type Session struct {
-	PID      int
-	CWD      string
-	State    State
-	Source   Source
-	Project  string        // Last 2 path components of the working directory
-	Topic    string        // Cleaned first user prompt
-	Branch   string        // Git branch from the transcript
-	Duration time.Duration // Wall-clock duration since process started
-	Messages int           // Approximate message count
+	Host     string        `json:"host,omitempty"` // ssh target for sessions on a remote host; empty when local
+	User     string        `json:"user,omitempty"` // Account running the process; set with --all-users
+	PID      int           `json:"pid"`
+	TTY      string        `json:"tty,omitempty"` // Controlling terminal from ps (empty for IDE sessions)
+	CWD      string        `json:"cwd"`
+	State    State         `json:"state"`
+	Source   Source        `json:"source"`
+	Project  string        `json:"project"`  // Last 2 path components of the working directory
+	Topic    string        `json:"topic"`    // Cleaned first user prompt
+	Branch   string        `json:"branch"`   // Git branch from the transcript
+	Duration time.Duration `json:"duration"` // Wall-clock duration since process started, in nanoseconds
+	Messages int           `json:"messages"` // Approximate message count
+
+	Model          string `json:"model,omitempty"`           // Latest assistant model, or --model from the command line
+	PermissionMode string `json:"permission_mode,omitempty"` // Latest transcript permission mode, or the launch flag
+	ContextTokens  int    `json:"context_tokens"`            // Prompt size of the latest assistant message
+	ContextLimit   int    `json:"context_limit"`             // Context window of the session's model
+	Compactions    int    `json:"compactions"`               // Times the context was compacted
+
+	CPUPercent float64 `json:"cpu_percent"` // CPU use of the claude process and its descendants; 100 is one core
+	Memory     int64   `json:"memory"`      // Resident memory of the claude process and its descendants, in bytes
+	Processes  int     `json:"processes"`   // The claude process plus its descendants; 0 when not sampled
+
+	Container        string `json:"container,omitempty"`         // Name of the container the session runs in
+	ContainerRuntime string `json:"container_runtime,omitempty"` // CLI managing the container: docker or podman
+	ContainerCWD     string `json:"container_cwd,omitempty"`     // Working directory as seen inside the container
+	ClaudeDir        string `json:"claude_dir,omitempty"`        // Config directory holding the transcript; empty for the default
+	IDEConnection    string `json:"ide_connection,omitempty"`    // IDE MCP transport and port from the lock file, e.g. "ws:37791"
+	NoAccess         bool   `json:"no_access,omitempty"`         // Another user's session whose transcripts cannot be read
+
+	SessionID      string    `json:"session_id,omitempty"`      // UUID of the transcript, used for claude --resume
+	TranscriptPath string    `json:"transcript_path,omitempty"` // Absolute path to the JSONL transcript
+	LastActivity   time.Time `json:"last_activity"`             // Transcript mtime
+	Lines          int       `json:"lines"`                     // Complete transcript lines written so far
+	Usage          Usage     `json:"usage"`                     // Token usage summed over assistant messages
+	Git            *git.Info `json:"git,omitempty"`             // Live repository state; nil outside a repository
+
+	Files     []FileTouch `json:"files,omitempty"`     // Files modified by edit tools, most recent first
+	Conflicts []Conflict  `json:"conflicts,omitempty"` // Files another live session has also edited
+}
+
+// CurrentBranch returns the branch checked out in the session's working
+// directory, falling back to the transcript's branch outside a repository.
+func (s Session) CurrentBranch() string {
+	if s.Git != nil && s.Git.Branch != "" {
+		return s.Git.Branch
+	}
+	return s.Branch
+}
+
+// PermissionsBypassed reports whether the session runs tools without asking.
+func (s Session) PermissionsBypassed() bool {
+	return s.PermissionMode == PermissionBypass
+}
+
+// BranchMismatch reports whether the checkout has moved to a different
+// branch than the one recorded in the transcript.
+func (s Session) BranchMismatch() bool {
+	return s.Git != nil && s.Git.Branch != "" && s.Branch != "" && !s.Git.Detached && s.Git.Branch != s.Branch
+}
+
+// Snapshot is the machine-readable form of one discovery pass, as emitted by
+// --format json.
+type Snapshot struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Hostname    string    `json:"hostname"`
+	Sessions    []Session `json:"sessions"`
 }
 
 // FormatDuration renders a duration as a compact human-readable string.
@@ -83,3 +163,40 @@ func FormatDuration(duration time.Duration) string {
 	}
 	return fmt.Sprintf("%ds", seconds)
 }
+
+// FormatSize renders a byte count as a compact human-readable string.
+// Examples: 512B, 4.2K, 13M, 1.1G
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+
+	value := float64(bytes)
+	suffixes := []string{"K", "M", "G", "T"}
+	for i, suffix := range suffixes {
+		value /= unit
+		if value < unit || i == len(suffixes)-1 {
+			if value < 10 {
+				return fmt.Sprintf("%.1f%s", value, suffix)
+			}
+			return fmt.Sprintf("%.0f%s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
+
+// FormatTokens renders a token count compactly.
+// Examples: 950, 12k, 1.4M, 23M
+func FormatTokens(tokens int) string {
+	switch {
+	case tokens >= 10_000_000:
+		return fmt.Sprintf("%.0fM", float64(tokens)/1_000_000)
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
+	case tokens >= 1_000:
+		return fmt.Sprintf("%.0fk", float64(tokens)/1_000)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
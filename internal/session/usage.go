@@ -0,0 +1,184 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Usage holds token counts as reported in assistant message usage blocks.
+type Usage struct {
+	InputTokens         int `json:"input_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	CacheCreationTokens int `json:"cache_creation_input_tokens"`
+	CacheReadTokens     int `json:"cache_read_input_tokens"`
+}
+
+// Total returns the sum of all token counts.
+func (u Usage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+// Add returns the element-wise sum of two usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:         u.InputTokens + other.InputTokens,
+		OutputTokens:        u.OutputTokens + other.OutputTokens,
+		CacheCreationTokens: u.CacheCreationTokens + other.CacheCreationTokens,
+		CacheReadTokens:     u.CacheReadTokens + other.CacheReadTokens,
+	}
+}
+
+// transcriptStats accumulates statistics over a transcript, advancing through
+// the file incrementally so that each refresh only parses appended lines.
+type transcriptStats struct {
+	Offset         int64                 // Byte offset just past the last parsed line
+	Lines          int                   // Complete lines parsed
+	Usage          Usage                 // Summed usage of distinct assistant messages
+	Model          string                // Model of the latest assistant message
+	ContextTokens  int                   // Input, cache read and cache creation tokens of the latest usage
+	PermissionMode string                // Latest permission mode recorded on a line
+	lastMessageID  string                // Assistant message ID of the last counted usage
+	files          map[string]*FileTouch // Files modified by edit tools, keyed by path
+
+	compactBoundaries int // compact_boundary system lines
+	compactSummaries  int // Compaction summaries injected as user messages
+}
+
+// compactions returns how many times the session's context was compacted.
+// Recent versions write a compact_boundary line and a summary message per
+// compaction; older ones only the summary.
+func (stats *transcriptStats) compactions() int {
+	return max(stats.compactBoundaries, stats.compactSummaries)
+}
+
+// statsCache persists transcript statistics across refresh cycles.
+// Key: transcript path. statsCacheMu also guards the cached statistics
+// themselves, which are updated in place.
+var (
+	statsCacheMu sync.Mutex
+	statsCache   = make(map[string]*transcriptStats)
+)
+
+// applyTranscriptStats brings a transcript's statistics up to date and
+// copies them onto session. Refreshes can overlap, so both steps run under
+// statsCacheMu.
+func applyTranscriptStats(transcriptPath string, size int64, session *Session) {
+	statsCacheMu.Lock()
+	defer statsCacheMu.Unlock()
+	updateTranscriptStats(transcriptPath, size).applyStats(session)
+}
+
+// updateTranscriptStats parses lines appended to a transcript since the last
+// call and returns its accumulated statistics. A transcript that shrank is
+// re-parsed from the start. The caller holds statsCacheMu.
+func updateTranscriptStats(transcriptPath string, size int64) *transcriptStats {
+	stats, known := statsCache[transcriptPath]
+	if !known || size < stats.Offset {
+		stats = &transcriptStats{}
+		statsCache[transcriptPath] = stats
+	}
+	if size == stats.Offset {
+		return stats
+	}
+
+	file, err := os.Open(transcriptPath)
+	if err != nil {
+		return stats
+	}
+	defer file.Close()
+
+	if _, seekErr := file.Seek(stats.Offset, io.SeekStart); seekErr != nil {
+		return stats
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if errors.Is(readErr, io.EOF) {
+			// A final line without a newline is only counted once it is
+			// complete JSON; otherwise the writer is mid-line, so wait
+			if len(line) == 0 || !json.Valid(line) {
+				return stats
+			}
+		} else if readErr != nil {
+			return stats
+		}
+
+		stats.Offset += int64(len(line))
+		stats.Lines++
+		stats.addLine(line)
+
+		if readErr != nil {
+			return stats
+		}
+	}
+}
+
+// addLine folds one transcript line into the statistics.
+func (stats *transcriptStats) addLine(line []byte) {
+	var entry jsonlLine
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return
+	}
+
+	if entry.PermissionMode != "" {
+		stats.PermissionMode = entry.PermissionMode
+	}
+	if entry.Type == "system" && entry.Subtype == "compact_boundary" {
+		stats.compactBoundaries++
+	}
+	if entry.IsCompactSummary {
+		stats.compactSummaries++
+	}
+
+	if entry.Type != "assistant" {
+		return
+	}
+
+	if entry.Message.Model != "" && entry.Message.Model != "<synthetic>" {
+		stats.Model = entry.Message.Model
+	}
+	stats.addEdits(entry)
+
+	if entry.Message.Usage == nil {
+		return
+	}
+
+	// Subagent messages report their own, separate context
+	if !entry.IsSidechain && entry.Message.Model != "<synthetic>" {
+		usage := entry.Message.Usage
+		stats.ContextTokens = usage.InputTokens + usage.CacheReadTokens + usage.CacheCreationTokens
+	}
+
+	// A single API response is split across one line per content block, each
+	// repeating the same message ID and usage; count it once
+	if entry.Message.ID != "" && entry.Message.ID == stats.lastMessageID {
+		return
+	}
+	stats.lastMessageID = entry.Message.ID
+	stats.Usage = stats.Usage.Add(*entry.Message.Usage)
+}
+
+// applyStats copies transcript statistics onto a session. The model and
+// permission mode recorded in the transcript override command-line flags,
+// since both can change mid-session; a [1m] flag still sets the window.
+func (stats *transcriptStats) applyStats(session *Session) {
+	launchModel := session.Model
+
+	session.Lines = stats.Lines
+	if stats.Model != "" {
+		session.Model = stats.Model
+	}
+	if stats.PermissionMode != "" {
+		session.PermissionMode = stats.PermissionMode
+	}
+	session.Usage = stats.Usage
+	session.Files = stats.filesTouched()
+	session.ContextTokens = stats.ContextTokens
+	session.ContextLimit = ContextWindow(launchModel, stats.ContextTokens)
+	session.Compactions = stats.compactions()
+}
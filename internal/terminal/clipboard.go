@@ -0,0 +1,25 @@
+package terminal
+
+import (
+	"encoding/base64"
+	"os"
+	"strings"
+)
+
+// ClipboardSequence returns the OSC 52 escape sequence that asks the terminal
+// emulator to place text on the system clipboard. Inside tmux the sequence is
+// wrapped in a DCS passthrough so it reaches the outer terminal.
+func ClipboardSequence(text string, inTmux bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if !inTmux {
+		return sequence
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// CopyToClipboard returns the clipboard sequence for text in the terminal
+// this process runs in. Callers emit it as part of their own output, so it
+// never lands in the middle of another escape sequence.
+func CopyToClipboard(text string) string {
+	return ClipboardSequence(text, os.Getenv("TMUX") != "")
+}
@@ -0,0 +1,80 @@
+package terminal
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNoPane is returned when no supported terminal multiplexer owns the TTY.
+var ErrNoPane = errors.New("session is not running in a supported terminal multiplexer")
+
+// Backend is a terminal multiplexer that can locate the pane attached to a
+// TTY and type text into it.
+type Backend interface {
+	// Name returns the short display name of the backend (e.g., "tmux").
+	Name() string
+
+	// FindPane returns the backend-specific pane target for a TTY, and false
+	// if the backend is unavailable or no pane owns the TTY.
+	FindPane(tty string) (string, bool)
+
+	// SendText types text into the pane followed by Enter.
+	SendText(pane string, text string) error
+
+	// Active reports whether cctop itself is running inside this backend.
+	Active() bool
+
+	// NewWindow opens a new window in dir running a shell command.
+	NewWindow(dir string, command string) error
+}
+
+// backends lists the supported multiplexers in lookup order.
+var backends = []Backend{
+	&Tmux{},
+}
+
+// Pane identifies a located pane and the backend that owns it.
+type Pane struct {
+	Backend Backend
+	Target  string
+}
+
+// Locate finds the pane running on the given TTY across all backends.
+// The TTY may be given as reported by ps (e.g., "ttys001", "pts/3") or as a
+// full device path.
+func Locate(tty string) (Pane, error) {
+	if tty == "" || tty == "??" || tty == "?" {
+		return Pane{}, ErrNoPane
+	}
+
+	devicePath := DevicePath(tty)
+	for _, backend := range backends {
+		if target, found := backend.FindPane(devicePath); found {
+			return Pane{Backend: backend, Target: target}, nil
+		}
+	}
+	return Pane{}, ErrNoPane
+}
+
+// Current returns the backend cctop is running inside, if any.
+func Current() (Backend, bool) {
+	for _, backend := range backends {
+		if backend.Active() {
+			return backend, true
+		}
+	}
+	return nil, false
+}
+
+// Send types text into the pane followed by Enter.
+func (p Pane) Send(text string) error {
+	return p.Backend.SendText(p.Target, text)
+}
+
+// DevicePath converts a ps-style TTY name to its /dev path.
+func DevicePath(tty string) string {
+	if strings.HasPrefix(tty, "/dev/") {
+		return tty
+	}
+	return "/dev/" + tty
+}
@@ -0,0 +1,66 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Tmux drives panes through the tmux command-line client.
+type Tmux struct{}
+
+// Name returns "tmux".
+func (t *Tmux) Name() string {
+	return "tmux"
+}
+
+// FindPane lists all tmux panes and returns the pane ID whose TTY matches.
+func (t *Tmux) FindPane(tty string) (string, bool) {
+	out, err := exec.Command("tmux", "list-panes", "-a", "-F", "#{pane_tty} #{pane_id}").Output()
+	if err != nil {
+		return "", false
+	}
+
+	paneID, found := ParseTmuxPanes(string(out))[tty]
+	return paneID, found
+}
+
+// SendText types text literally into the pane, then presses Enter as a
+// separate key so that tmux does not interpret the text as key names.
+func (t *Tmux) SendText(pane string, text string) error {
+	if out, err := exec.Command("tmux", "send-keys", "-t", pane, "-l", text).CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux send-keys: %s", strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.Command("tmux", "send-keys", "-t", pane, "Enter").CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux send-keys: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Active reports whether the current process runs inside a tmux client.
+func (t *Tmux) Active() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// NewWindow opens a tmux window in dir running command.
+func (t *Tmux) NewWindow(dir string, command string) error {
+	if out, err := exec.Command("tmux", "new-window", "-c", dir, command).CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux new-window: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ParseTmuxPanes parses `tmux list-panes -F "#{pane_tty} #{pane_id}"` output
+// into a map of TTY device path to pane ID.
+func ParseTmuxPanes(output string) map[string]string {
+	panes := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		panes[fields[0]] = fields[1]
+	}
+	return panes
+}
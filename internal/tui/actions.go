@@ -0,0 +1,308 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/session"
+	"github.com/Jevs21/cctop/internal/terminal"
+)
+
+// confirmation holds a pending action awaiting a y/n answer.
+type confirmation struct {
+	prompt string
+	action tea.Cmd
+}
+
+// statusLine holds the most recent action result shown below the table.
+type statusLine struct {
+	text      string
+	isError   bool
+	at        time.Time
+	clipboard string // OSC 52 sequence drawn with the status; see renderStatus
+}
+
+// actionResultMsg carries the outcome of a background session action.
+type actionResultMsg struct {
+	text      string
+	isError   bool
+	clipboard string // Clipboard escape sequence for the renderer to write
+}
+
+// startReply opens the reply input for the selected session. Only sessions
+// that are waiting on the user and run in a terminal can receive a reply.
+func (m model) startReply() (tea.Model, tea.Cmd) {
+	selected, ok := m.selectedSession()
+	if !ok {
+		return m, nil
+	}
+
+	if selected.Host != "" {
+		m.status = statusLine{text: "Reply: sessions on remote hosts are read-only", isError: true, at: time.Now()}
+		return m, nil
+	}
+	if selected.State != session.StateWaiting && selected.State != session.StateInput {
+		m.status = statusLine{text: "Reply: session is not waiting for input", isError: true, at: time.Now()}
+		return m, nil
+	}
+	if selected.TTY == "" {
+		m.status = statusLine{text: "Reply: only terminal sessions can receive input", isError: true, at: time.Now()}
+		return m, nil
+	}
+
+	m.replyTarget = selected
+	m.replyInput.SetValue("")
+	m.mode = ModeReply
+	cmd := m.replyInput.Focus()
+	return m, cmd
+}
+
+func (m model) updateReply(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		text := strings.TrimSpace(m.replyInput.Value())
+		m.replyInput.Blur()
+		if text == "" {
+			m.mode = ModeNormal
+			return m, nil
+		}
+		m.confirm = confirmation{
+			prompt: fmt.Sprintf("Send %q to %s (PID %d)?", truncateString(text, 40), m.replyTarget.Project, m.replyTarget.PID),
+			action: sendReplyCmd(m.replyTarget, text),
+		}
+		m.mode = ModeConfirm
+		return m, nil
+	case "esc":
+		m.replyInput.Blur()
+		m.mode = ModeNormal
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.replyInput, cmd = m.replyInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		action := m.confirm.action
+		m.confirm = confirmation{}
+		m.mode = ModeNormal
+		return m, action
+	case "n", "N", "esc", "q":
+		m.confirm = confirmation{}
+		m.mode = ModeNormal
+	}
+	return m, nil
+}
+
+// sendReplyCmd types text into the terminal pane running the session.
+func sendReplyCmd(target session.Session, text string) tea.Cmd {
+	return func() tea.Msg {
+		pane, err := terminal.Locate(target.TTY)
+		if err != nil {
+			return actionResultMsg{text: "Reply failed: " + err.Error(), isError: true}
+		}
+		if err := pane.Send(text); err != nil {
+			return actionResultMsg{text: "Reply failed: " + err.Error(), isError: true}
+		}
+		return actionResultMsg{text: fmt.Sprintf("Sent reply to %s via %s", target.Project, pane.Backend.Name())}
+	}
+}
+
+// actionTargets returns the marked sessions that are still visible, or the
+// session under the cursor when nothing is marked. Sessions on remote hosts
+// are never targets, since their PIDs belong to another machine.
+func (m model) actionTargets() []session.Session {
+	var targets []session.Session
+	for _, s := range m.filteredSessions() {
+		if markable(s) && m.marked[s.PID] {
+			targets = append(targets, s)
+		}
+	}
+	if len(targets) > 0 {
+		return targets
+	}
+
+	if selected, ok := m.selectedSession(); ok && selected.Host == "" {
+		return []session.Session{selected}
+	}
+	return nil
+}
+
+// markable reports whether a session can be marked for bulk actions: it
+// has a live local process.
+func markable(s session.Session) bool {
+	return s.PID > 0 && s.Host == ""
+}
+
+// pruneMarked drops marks for PIDs that no longer belong to a session.
+func (m *model) pruneMarked() {
+	live := make(map[int]bool, len(m.sessions))
+	for _, s := range m.sessions {
+		live[s.PID] = true
+	}
+	for pid := range m.marked {
+		if !live[pid] {
+			delete(m.marked, pid)
+		}
+	}
+}
+
+// confirmTerminate asks for confirmation before sending SIGTERM to the targets.
+func (m model) confirmTerminate() (tea.Model, tea.Cmd) {
+	targets := m.actionTargets()
+	if len(targets) == 0 {
+		return m, nil
+	}
+
+	var prompt string
+	if len(targets) == 1 {
+		prompt = fmt.Sprintf("Terminate %s (PID %d)?", targets[0].Project, targets[0].PID)
+	} else {
+		prompt = fmt.Sprintf("Terminate %d sessions?", len(targets))
+	}
+
+	m.confirm = confirmation{
+		prompt: prompt,
+		action: signalSessionsCmd(targets, syscall.SIGTERM),
+	}
+	m.mode = ModeConfirm
+	return m, nil
+}
+
+// signalSessionsCmd sends sig to every target and summarizes the outcomes.
+func signalSessionsCmd(targets []session.Session, sig syscall.Signal) tea.Cmd {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		signalName := "SIGINT"
+		if sig == syscall.SIGTERM {
+			signalName = "SIGTERM"
+		}
+
+		if len(targets) == 1 {
+			result := process.Signal(targets[0].PID, sig)
+			return actionResultMsg{
+				text:    fmt.Sprintf("%s %s: %s (PID %d)", signalName, targets[0].Project, result, targets[0].PID),
+				isError: result != process.SignalSent,
+			}
+		}
+
+		counts := make(map[process.SignalResult]int)
+		for _, target := range targets {
+			counts[process.Signal(target.PID, sig)]++
+		}
+
+		var parts []string
+		for _, result := range []process.SignalResult{process.SignalSent, process.SignalPermissionDenied, process.SignalAlreadyExited, process.SignalFailed} {
+			if counts[result] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[result], result))
+			}
+		}
+		return actionResultMsg{
+			text:    fmt.Sprintf("%s to %d sessions: %s", signalName, len(targets), strings.Join(parts, ", ")),
+			isError: counts[process.SignalSent] != len(targets),
+		}
+	}
+}
+
+// copyResumeCmd copies the selected session's resume command to the
+// clipboard via OSC 52. The sequence travels with the status message and is
+// written by the renderer: writing it from the command's goroutine could
+// interleave it with a frame being drawn.
+func copyResumeCmd(target session.Session) tea.Cmd {
+	return func() tea.Msg {
+		command := target.ResumeCommand()
+		if command == "" {
+			return actionResultMsg{text: "Copy failed: session ID unknown", isError: true}
+		}
+		return actionResultMsg{text: "Copied: " + command, clipboard: terminal.CopyToClipboard(command)}
+	}
+}
+
+// openResumeCmd launches claude --resume for the session in a new window of
+// the multiplexer cctop is running in.
+func openResumeCmd(target session.Session) tea.Cmd {
+	return func() tea.Msg {
+		if target.SessionID == "" {
+			return actionResultMsg{text: "Open failed: session ID unknown", isError: true}
+		}
+		backend, ok := terminal.Current()
+		if !ok {
+			return actionResultMsg{text: "Open failed: cctop is not running inside tmux", isError: true}
+		}
+		dir, command := target.CWD, "claude --resume "+session.ShellQuote(target.SessionID)
+		if target.Host != "" || target.Container != "" {
+			// The transcript is on another machine or inside a container, so
+			// resume there
+			dir, _ = os.UserHomeDir()
+			command = target.ResumeCommand()
+		}
+		if err := backend.NewWindow(dir, command); err != nil {
+			return actionResultMsg{text: "Open failed: " + err.Error(), isError: true}
+		}
+		return actionResultMsg{text: fmt.Sprintf("Resumed %s in a new %s window", target.Project, backend.Name())}
+	}
+}
+
+// renderReply renders the reply input view.
+func (m model) renderReply() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+
+	b.WriteString(headerStyle.Width(width).Render(" cctop -- Reply to Session"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("  %s  %s\n", detailLabelStyle.Render(fmt.Sprintf("%-10s", "Session")), m.replyTarget.Project))
+	b.WriteString(fmt.Sprintf("  %s  %s\n", detailLabelStyle.Render(fmt.Sprintf("%-10s", "Topic")), m.replyTarget.Topic))
+	b.WriteString("\n")
+	b.WriteString(filterPromptStyle.Render("  Reply: "))
+	b.WriteString(m.replyInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("  enter: review  esc: cancel"))
+
+	return b.String()
+}
+
+// renderConfirm renders a y/n confirmation for the pending action.
+func (m model) renderConfirm() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+
+	b.WriteString(headerStyle.Width(width).Render(" cctop -- Confirm"))
+	b.WriteString("\n\n")
+	b.WriteString(filterPromptStyle.Render("  " + m.confirm.prompt))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("  y: confirm  n/esc: cancel"))
+
+	return b.String()
+}
+
+// renderStatus returns the styled status line, or an empty string once the
+// last result has expired.
+func (m model) renderStatus() string {
+	if m.status.text == "" || time.Since(m.status.at) > statusDisplayDuration {
+		return ""
+	}
+	if m.status.isError {
+		return statusErrorStyle.Render("  " + m.status.text)
+	}
+	// The clipboard sequence is zero-width; the renderer only rewrites
+	// changed lines, so it reaches the terminal when the status appears
+	return m.status.clipboard + statusOKStyle.Render("  "+m.status.text)
+}
@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/activity"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const (
+	// sparkMinutes is how many minutes the ACTIVITY column covers, one
+	// character per minute.
+	sparkMinutes = 12
+
+	// graphHeight is the number of rows in the detail view's activity graphs.
+	graphHeight = 4
+)
+
+// trackActivity feeds the refreshed sessions' line and token counts to the
+// activity tracker.
+func (m *model) trackActivity(now time.Time) {
+	samples := make([]activity.Sample, len(m.sessions))
+	for i, s := range m.sessions {
+		samples[i] = activity.Sample{Key: sessionKey(s), Lines: s.Lines, Tokens: s.Usage.Total()}
+	}
+	m.activity.Track(now, samples)
+}
+
+// activitySeries returns a session's per-minute activity over the last
+// minutes, oldest first.
+func (m model) activitySeries(s session.Session, minutes int, metric activity.Metric) []int {
+	return m.activity.Series(sessionKey(s), m.now, minutes, metric)
+}
+
+// recentActivity sums a session's tokens over the ACTIVITY column's window,
+// for sorting.
+func (m model) recentActivity(s session.Session) int {
+	total := 0
+	for _, value := range m.activitySeries(s, sparkMinutes, activity.Tokens) {
+		total += max(value, 0)
+	}
+	return total
+}
+
+// renderActivity renders the detail view's tokens and lines per minute
+// graphs over the last activityMinutes, narrowed to fit the terminal.
+func (m model) renderActivity(s session.Session, width int) string {
+	minutes := min(activity.Minutes, width-16)
+	if minutes < sparkMinutes {
+		return ""
+	}
+	if !m.activity.Tracked(sessionKey(s)) {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("  %s\n", detailLabelStyle.Render(fmt.Sprintf("Activity (last %d min)", minutes))))
+	for _, graph := range []struct {
+		label  string
+		metric activity.Metric
+	}{
+		{"tokens/min", activity.Tokens},
+		{"lines/min", activity.Lines},
+	} {
+		series := m.activitySeries(s, minutes, graph.metric)
+		peak := activity.Peak(series)
+		peakText := fmt.Sprintf("%d", peak)
+		if graph.metric == activity.Tokens {
+			peakText = session.FormatTokens(peak)
+		}
+
+		for row, line := range activity.Graph(series, graphHeight) {
+			label := ""
+			switch row {
+			case 0:
+				label = peakText
+			case graphHeight - 1:
+				label = graph.label
+			}
+			b.WriteString(fmt.Sprintf("    %s %s\n", activityStyle.Render(line), dimStyle.Render(label)))
+		}
+	}
+	return b.String()
+}
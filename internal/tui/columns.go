@@ -0,0 +1,611 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Jevs21/cctop/internal/activity"
+	"github.com/Jevs21/cctop/internal/git"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// column describes one column of the session table.
+type column struct {
+	id         string
+	title      string
+	width      int  // Fixed width; 0 for a flexible column
+	flex       int  // Share of the leftover width for flexible columns
+	minWidth   int  // Minimum width for flexible columns
+	maxWidth   int  // Fixed columns grow to fit their widest value up to this
+	alignRight bool // Right-align the cell (numbers and durations)
+	optional   bool // Dropped when the terminal is too narrow
+	priority   int  // Optional columns with the lowest priority are dropped first
+	descFirst  bool // Sort descending when first selected with s
+
+	// value returns the plain cell text
+	value func(m model, s session.Session) string
+	// compare orders two sessions ascending by this column
+	compare func(m model, a, b session.Session) int
+}
+
+// columns is the registry of all table columns in their canonical order.
+var columns = []column{
+	{
+		id: "state", title: "ST", width: 3,
+		value:   func(_ model, s session.Session) string { return s.State.String() },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.State.Priority(), b.State.Priority()) },
+	},
+	{
+		id: "source", title: "SRC", width: 7, maxWidth: 20,
+		value:   func(_ model, s session.Session) string { return sourceLabel(s) },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.Source.Type, b.Source.Type) },
+	},
+	{
+		id: "host", title: "HOST", width: 12,
+		value:   func(_ model, s session.Session) string { return hostLabel(s) },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(hostLabel(a), hostLabel(b)) },
+	},
+	{
+		id: "user", title: "USER", width: 8, maxWidth: 16,
+		value:   func(_ model, s session.Session) string { return s.User },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.User, b.User) },
+	},
+	{
+		id: "pid", title: "PID", width: 7, alignRight: true,
+		value: func(_ model, s session.Session) string {
+			if s.PID <= 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d", s.PID)
+		},
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.PID, b.PID) },
+	},
+	{
+		id: "project", title: "PROJECT", flex: projectWidthPercent, minWidth: minProjectColWidth,
+		value:   func(_ model, s session.Session) string { return s.Project },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.Project, b.Project) },
+	},
+	{
+		id: "topic", title: "TOPIC", flex: 100 - projectWidthPercent, minWidth: minTopicColWidth,
+		value:   func(_ model, s session.Session) string { return topicLabel(s) },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(topicLabel(a), topicLabel(b)) },
+	},
+	{
+		id: "branch", title: "BRANCH", width: 16, optional: true, priority: 8,
+		value:   func(_ model, s session.Session) string { return s.CurrentBranch() },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.CurrentBranch(), b.CurrentBranch()) },
+	},
+	{
+		id: "git", title: "GIT", width: 9, optional: true, priority: 6,
+		value: func(_ model, s session.Session) string { return gitStatusShort(s.Git) },
+		compare: func(_ model, a, b session.Session) int {
+			return cmp.Compare(gitDirty(a.Git), gitDirty(b.Git))
+		},
+	},
+	{
+		id: "worktree", title: "WORKTREE", width: 12,
+		value: func(_ model, s session.Session) string { return gitWorktree(s.Git) },
+		compare: func(_ model, a, b session.Session) int {
+			return strings.Compare(gitWorktree(a.Git), gitWorktree(b.Git))
+		},
+	},
+	{
+		id: "model", title: "MODEL", width: 10, optional: true, priority: 4,
+		value:   func(_ model, s session.Session) string { return session.ShortModelName(s.Model) },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.Model, b.Model) },
+	},
+	{
+		id: "mode", title: "MODE", width: 6, optional: true, priority: 5,
+		value:   func(_ model, s session.Session) string { return permissionModeLabel(s.PermissionMode) },
+		compare: func(_ model, a, b session.Session) int { return strings.Compare(a.PermissionMode, b.PermissionMode) },
+	},
+	{
+		id: "ctx", title: "CTX%", width: 4, alignRight: true, optional: true, priority: 7, descFirst: true,
+		value:   func(_ model, s session.Session) string { return contextPercentText(s) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.ContextPercent(), b.ContextPercent()) },
+	},
+	{
+		id: "files", title: "FILES", width: 5, alignRight: true, descFirst: true,
+		value:   func(_ model, s session.Session) string { return fmt.Sprintf("%d", len(s.Files)) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(len(a.Files), len(b.Files)) },
+	},
+	{
+		id: "msgs", title: "MSGS", width: 5, alignRight: true, descFirst: true,
+		value:   func(_ model, s session.Session) string { return fmt.Sprintf("%d", s.Messages) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.Messages, b.Messages) },
+	},
+	{
+		id: "tokens", title: "TOKENS", width: 7, alignRight: true, descFirst: true,
+		value:   func(_ model, s session.Session) string { return session.FormatTokens(s.Usage.Total()) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.Usage.Total(), b.Usage.Total()) },
+	},
+	{
+		id: "in_state", title: "IN STATE", width: 8, alignRight: true, descFirst: true,
+		value:   func(m model, s session.Session) string { return session.FormatDuration(m.timeInState(s)) },
+		compare: func(m model, a, b session.Session) int { return cmp.Compare(m.timeInState(a), m.timeInState(b)) },
+	},
+	{
+		id: "activity", title: "ACTIVITY", width: sparkMinutes, optional: true, priority: 1, descFirst: true,
+		value: func(m model, s session.Session) string {
+			return activity.Sparkline(m.activitySeries(s, sparkMinutes, activity.Tokens))
+		},
+		compare: func(m model, a, b session.Session) int { return cmp.Compare(m.recentActivity(a), m.recentActivity(b)) },
+	},
+	{
+		id: "cpu", title: "CPU%", width: 4, alignRight: true, optional: true, priority: 3, descFirst: true,
+		value:   func(_ model, s session.Session) string { return cpuText(s) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.CPUPercent, b.CPUPercent) },
+	},
+	{
+		id: "mem", title: "MEM", width: 5, alignRight: true, optional: true, priority: 2, descFirst: true,
+		value:   func(_ model, s session.Session) string { return memoryText(s) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.Memory, b.Memory) },
+	},
+	{
+		id: "dur", title: "DUR", width: 7, alignRight: true, descFirst: true,
+		value:   func(_ model, s session.Session) string { return session.FormatDuration(s.Duration) },
+		compare: func(_ model, a, b session.Session) int { return cmp.Compare(a.Duration, b.Duration) },
+	},
+}
+
+// defaultColumns are the columns shown when no view selects others.
+var defaultColumns = []string{"state", "source", "project", "topic", "branch", "git", "model", "mode", "ctx", "activity", "cpu", "mem", "dur"}
+
+// defaultSortKeys is the sort order used when no view selects another.
+var defaultSortKeys = []sortKey{{column: "state"}}
+
+// maxSortKeys caps how many keys accumulate as s selects new primary columns.
+const maxSortKeys = 3
+
+// findColumn returns the registered column with the given ID.
+func findColumn(id string) (column, bool) {
+	for _, col := range columns {
+		if col.id == id {
+			return col, true
+		}
+	}
+	return column{}, false
+}
+
+// columnIDs returns the IDs of all registered columns.
+func columnIDs() []string {
+	ids := make([]string, len(columns))
+	for i, col := range columns {
+		ids[i] = col.id
+	}
+	return ids
+}
+
+// parseColumns validates a list of column IDs.
+func parseColumns(ids []string) ([]string, error) {
+	for _, id := range ids {
+		if _, ok := findColumn(id); !ok {
+			return nil, fmt.Errorf("unknown column %q (want one of %s)", id, strings.Join(columnIDs(), ", "))
+		}
+	}
+	return ids, nil
+}
+
+// sortKey is one level of a multi-key sort.
+type sortKey struct {
+	column string
+	desc   bool
+}
+
+// parseSortKeys parses sort keys such as ["state", "-in_state", "project"]:
+// each names a column, with a "-" prefix for descending order.
+func parseSortKeys(specs []string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		key := sortKey{column: strings.TrimPrefix(strings.TrimPrefix(spec, "-"), "+"), desc: strings.HasPrefix(spec, "-")}
+		if _, ok := findColumn(key.column); !ok {
+			return nil, fmt.Errorf("unknown sort column %q (want one of %s)", key.column, strings.Join(columnIDs(), ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParseSortSpec parses a comma-separated sort specification such as
+// "state,-in_state,project" and reports an error for unknown columns.
+func ParseSortSpec(spec string) ([]string, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	specs := strings.Split(spec, ",")
+	if _, err := parseSortKeys(specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// sortKeysName renders sort keys for the help line, e.g. "state↑ in_state↓".
+func sortKeysName(keys []sortKey) string {
+	var parts []string
+	for _, key := range keys {
+		arrow := "↑"
+		if key.desc {
+			arrow = "↓"
+		}
+		parts = append(parts, key.column+arrow)
+	}
+	return strings.Join(parts, " ")
+}
+
+// compareSessions orders two sessions by the model's sort keys.
+func (m model) compareSessions(a, b session.Session) int {
+	for _, key := range m.sortKeys {
+		col, ok := findColumn(key.column)
+		if !ok {
+			continue
+		}
+		result := col.compare(m, a, b)
+		if key.desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// cycleSortColumn makes the next column (in the visible order) the primary
+// sort key, keeping the previous keys as tie-breakers.
+func (m *model) cycleSortColumn() {
+	visible := m.columns
+	next := visible[0]
+	if len(m.sortKeys) > 0 {
+		for i, id := range visible {
+			if id == m.sortKeys[0].column {
+				next = visible[(i+1)%len(visible)]
+				break
+			}
+		}
+	}
+
+	col, _ := findColumn(next)
+	keys := []sortKey{{column: next, desc: col.descFirst}}
+	for _, key := range m.sortKeys {
+		if key.column != next && len(keys) < maxSortKeys {
+			keys = append(keys, key)
+		}
+	}
+	m.sortKeys = keys
+}
+
+// toggleSortDirection flips the direction of the primary sort key.
+func (m *model) toggleSortDirection() {
+	if len(m.sortKeys) == 0 {
+		m.sortKeys = []sortKey{{column: m.columns[0]}}
+	}
+	keys := append([]sortKey(nil), m.sortKeys...)
+	keys[0].desc = !keys[0].desc
+	m.sortKeys = keys
+}
+
+// timeInState returns how long a session has been in its current state,
+// as observed since cctop started.
+func (m model) timeInState(s session.Session) time.Duration {
+	since, ok := m.stateSince[sessionKey(s)]
+	if !ok || since.state != s.State {
+		return 0
+	}
+	return m.now.Sub(since.at)
+}
+
+// stateChange records when a session entered its current state.
+type stateChange struct {
+	state session.State
+	at    time.Time
+}
+
+// sessionKey identifies a session across refreshes: live sessions by PID,
+// exited ones by transcript, prefixed by the host for remote sessions.
+func sessionKey(s session.Session) string {
+	key := "transcript:" + s.TranscriptPath
+	if s.PID > 0 {
+		key = fmt.Sprintf("pid:%d", s.PID)
+	}
+	if s.Host != "" {
+		return s.Host + "/" + key
+	}
+	return key
+}
+
+// trackStates updates stateSince after a refresh, dropping sessions that are
+// gone. Sessions seen for the first time are assumed to have entered their
+// state at their last transcript write, or now when unknown.
+func (m *model) trackStates(now time.Time) {
+	next := make(map[string]stateChange, len(m.sessions))
+	for _, s := range m.sessions {
+		key := sessionKey(s)
+		if previous, ok := m.stateSince[key]; ok && previous.state == s.State {
+			next[key] = previous
+			continue
+		}
+		at := now
+		if _, seen := m.stateSince[key]; !seen && !s.LastActivity.IsZero() && s.LastActivity.Before(now) {
+			at = s.LastActivity
+		}
+		next[key] = stateChange{state: s.State, at: at}
+	}
+	m.stateSince = next
+	m.now = now
+}
+
+// layoutColumns computes widths for the visible columns at the given
+// terminal width. Optional columns are dropped one at a time, lowest
+// priority first, until the flexible columns fit their minimum widths; the
+// flexible columns then share what remains.
+func (m model) layoutColumns(terminalWidth int) ([]column, []int) {
+	var visible []column
+	for _, id := range m.columns {
+		if col, ok := findColumn(id); ok {
+			for _, s := range m.sessions {
+				if col.maxWidth > 0 {
+					col.width = min(max(col.width, utf8.RuneCountInString(col.value(m, s))), col.maxWidth)
+				}
+			}
+			visible = append(visible, col)
+		}
+	}
+
+	// Row prefix (mark and cursor) plus one space before each column
+	remaining := terminalWidth - 2
+	totalFlex, minFlex := 0, 0
+	for _, col := range visible {
+		remaining -= col.width + 1
+		totalFlex += col.flex
+		minFlex += col.minWidth
+	}
+
+	for remaining < minFlex {
+		drop := -1
+		for i, col := range visible {
+			if col.optional && (drop < 0 || col.priority < visible[drop].priority) {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			break
+		}
+		remaining += visible[drop].width + 1
+		visible = slices.Delete(visible, drop, drop+1)
+	}
+
+	// Each flexible column takes its share, leaving the later ones their
+	// minimum; the last one takes the rest so the row fills the width exactly
+	widths := make([]int, len(visible))
+	left := remaining
+	for i, col := range visible {
+		if col.flex == 0 {
+			widths[i] = col.width
+			continue
+		}
+		minFlex -= col.minWidth
+		if lastFlex(visible, i) {
+			widths[i] = max(left, col.minWidth)
+			continue
+		}
+		widths[i] = max(min(remaining*col.flex/totalFlex, left-minFlex), col.minWidth)
+		left -= widths[i]
+	}
+	return visible, widths
+}
+
+// lastFlex reports whether visible[i] is the last flexible column.
+func lastFlex(visible []column, i int) bool {
+	for _, col := range visible[i+1:] {
+		if col.flex > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// renderColumnHeaders renders the table header line.
+func renderColumnHeaders(visible []column, widths []int) string {
+	var b strings.Builder
+	b.WriteString("  ")
+	for i, col := range visible {
+		if col.alignRight {
+			b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %*s", widths[i], col.title)))
+		} else {
+			b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", widths[i], col.title)))
+		}
+	}
+	return b.String()
+}
+
+// permissionModeLabels abbreviates Claude Code permission modes for the
+// MODE column.
+var permissionModeLabels = map[string]string{
+	"default":                "ask",
+	"acceptEdits":            "edits",
+	"plan":                   "plan",
+	session.PermissionBypass: "bypass",
+}
+
+// permissionModeLabel returns the MODE column text for a permission mode;
+// unknown modes are shown as recorded.
+func permissionModeLabel(mode string) string {
+	if label, ok := permissionModeLabels[mode]; ok {
+		return label
+	}
+	return mode
+}
+
+// contextWarnPercent is the context utilisation from which CTX% turns orange;
+// it turns red at session.NearCompactionPercent.
+const contextWarnPercent = 60
+
+// contextPercentText renders the CTX% cell, blank until usage is known.
+func contextPercentText(s session.Session) string {
+	percent := s.ContextPercent()
+	if percent < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d%%", percent)
+}
+
+// gitStatusShort renders dirty and ahead/behind counts compactly, e.g.
+// "*3 \u21911\u21932", or "\u2713" for a clean tree in sync with upstream.
+func gitStatusShort(info *git.Info) string {
+	if info == nil || !info.HasStatus {
+		return ""
+	}
+
+	var parts []string
+	if info.Dirty > 0 {
+		parts = append(parts, fmt.Sprintf("*%d", info.Dirty))
+	}
+	if info.Ahead > 0 || info.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("\u2191%d\u2193%d", info.Ahead, info.Behind))
+	}
+	if len(parts) == 0 {
+		return "\u2713"
+	}
+	return strings.Join(parts, " ")
+}
+
+// gitStatusLong renders the git status for the detail view.
+func gitStatusLong(info *git.Info) string {
+	if info == nil || !info.HasStatus {
+		return ""
+	}
+
+	text := "clean"
+	if info.Dirty > 0 {
+		text = fmt.Sprintf("%d changed files", info.Dirty)
+	}
+	switch {
+	case !info.HasUpstream:
+		text += ", no upstream"
+	case info.Ahead > 0 || info.Behind > 0:
+		text += fmt.Sprintf(", %d ahead, %d behind upstream", info.Ahead, info.Behind)
+	default:
+		text += ", up to date with upstream"
+	}
+	return text
+}
+
+// gitDirty returns the dirty file count, or -1 outside a repository.
+func gitDirty(info *git.Info) int {
+	if info == nil || !info.HasStatus {
+		return -1
+	}
+	return info.Dirty
+}
+
+// gitWorktree returns the linked worktree name, if any.
+func gitWorktree(info *git.Info) string {
+	if info == nil {
+		return ""
+	}
+	return info.Worktree
+}
+
+// cpuText returns the CPU% of a session's process tree, or "-" when it has
+// no sampled process (exited, or processes could not be listed).
+func cpuText(s session.Session) string {
+	if s.Processes == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", s.CPUPercent)
+}
+
+// memoryText returns the resident memory of a session's process tree, or
+// "-" when it has no sampled process.
+func memoryText(s session.Session) string {
+	if s.Processes == 0 {
+		return "-"
+	}
+	return session.FormatSize(s.Memory)
+}
+
+// sourceLabel returns the session's source preceded by its IDE's icon from
+// the registry, if it has one.
+func sourceLabel(s session.Session) string {
+	if ide, ok := session.IDEByName(s.Source.Type); ok && ide.Icon != "" {
+		return ide.Icon + " " + s.Source.Type
+	}
+	return s.Source.Type
+}
+
+// sourceStyle returns the style of an IDE session's source: its registry
+// colour, or the default IDE style.
+func sourceStyle(s session.Session) lipgloss.Style {
+	if ide, ok := session.IDEByName(s.Source.Type); ok && ide.Color != "" {
+		return ideSourceStyle.Foreground(lipgloss.Color(ide.Color))
+	}
+	return ideSourceStyle
+}
+
+// renderCell renders one padded, styled cell of a session row.
+func (m model) renderCell(col column, width int, s session.Session, textStyleFn func(string) string) string {
+	switch col.id {
+	case "state":
+		if len(s.Conflicts) > 0 {
+			// Another live session is editing the same files
+			return stateIconStyled(s.State, 2) + conflictStyle.Render(fmt.Sprintf("%-*s", width-2, "!"))
+		}
+		return stateIconStyled(s.State, width)
+	case "source":
+		padded := fmt.Sprintf("%-*s", width, truncateString(sourceLabel(s), width))
+		switch {
+		case s.State == session.StateExited:
+			return exitedStyle.Render(padded)
+		case s.Source.Type == "CLI":
+			return cliSourceStyle.Render(padded)
+		default:
+			return sourceStyle(s).Render(padded)
+		}
+	}
+
+	if col.id == "mode" && s.PermissionsBypassed() && s.State != session.StateExited {
+		// Tools run without asking, so make it hard to miss
+		return bypassStyle.Render(fmt.Sprintf("%-*s", width, truncateString(permissionModeLabel(s.PermissionMode), width)))
+	}
+
+	if col.id == "ctx" && s.State != session.StateExited {
+		padded := fmt.Sprintf("%*s", width, contextPercentText(s))
+		switch percent := s.ContextPercent(); {
+		case s.NearCompaction():
+			return contextCriticalStyle.Render(padded)
+		case percent >= contextWarnPercent:
+			return contextWarnStyle.Render(padded)
+		}
+	}
+
+	if (col.id == "cpu" && s.CPUPercent >= heavyCPUPercent) || (col.id == "mem" && s.Memory >= heavyMemory) {
+		return contextWarnStyle.Render(fmt.Sprintf("%*s", width, col.value(m, s)))
+	}
+
+	if col.id == "activity" {
+		// Sparkline glyphs are multi-byte, so skip byte-based truncation
+		padded := fmt.Sprintf("%-*s", width, col.value(m, s))
+		if s.State == session.StateExited {
+			return exitedStyle.Render(padded)
+		}
+		return activityStyle.Render(padded)
+	}
+
+	if col.id == "branch" && s.BranchMismatch() {
+		// The checkout moved away from the transcript's branch
+		return branchMismatchStyle.Render(fmt.Sprintf("%-*s", width, truncateString(s.CurrentBranch()+"!", width)))
+	}
+
+	text := truncateString(col.value(m, s), width)
+	if col.alignRight {
+		return textStyleFn(fmt.Sprintf("%*s", width, text))
+	}
+	return textStyleFn(fmt.Sprintf("%-*s", width, text))
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// maxDetailFiles caps the files-touched list in the detail view.
+const maxDetailFiles = 12
+
+// renderFilesTouched renders the detail view's list of edited files, most
+// recent first, with files another live session also edited flagged.
+func renderFilesTouched(s session.Session, width int) string {
+	if len(s.Files) == 0 {
+		return ""
+	}
+
+	conflicts := make(map[string][]string)
+	for _, conflict := range s.Conflicts {
+		conflicts[conflict.Path] = append(conflicts[conflict.Path], fmt.Sprintf("%s (PID %d)", conflict.OtherProject, conflict.OtherPID))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("  %s\n", detailLabelStyle.Render(fmt.Sprintf("Files touched (%d)", len(s.Files)))))
+
+	base := s.CWD
+	if s.Git != nil {
+		base = s.Git.Root
+	}
+	pathWidth := max(width-24, minTopicColWidth)
+
+	for i, touch := range s.Files {
+		if i == maxDetailFiles {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("    ... %d more files", len(s.Files)-maxDetailFiles)))
+			b.WriteString("\n")
+			break
+		}
+
+		line := fmt.Sprintf("    %3d× %8s  %s", touch.Edits, touchedAgo(touch.LastTouched), truncateString(displayPath(touch.Path, base), pathWidth))
+		if others, conflicting := conflicts[touch.Path]; conflicting {
+			b.WriteString(conflictStyle.Render(line + "  ⚠ also edited by " + strings.Join(others, ", ")))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// displayPath shows path relative to base when it lies inside it.
+func displayPath(path, base string) string {
+	if base == "" {
+		return path
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// touchedAgo renders how long ago a file was last edited.
+func touchedAgo(at time.Time) string {
+	if at.IsZero() {
+		return "-"
+	}
+	return session.FormatDuration(time.Since(at)) + " ago"
+}
+
+// conflictCount returns how many live sessions share an edited file with
+// another live session.
+func (m model) conflictCount() int {
+	count := 0
+	for _, s := range m.sessions {
+		if len(s.Conflicts) > 0 {
+			count++
+		}
+	}
+	return count
+}
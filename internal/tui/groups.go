@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jevs21/cctop/internal/grouping"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// tableRows returns the rows of the session table: the filtered sessions,
+// interleaved with group headers when grouping is on. Sessions in collapsed
+// groups are omitted.
+func (m model) tableRows() []grouping.Row {
+	return grouping.Rows(m.groupBy, m.filteredSessions(), m.collapsed)
+}
+
+// selectedRow returns the table row under the cursor, if any.
+func (m model) selectedRow() (grouping.Row, bool) {
+	rows := m.tableRows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return grouping.Row{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// setGroupCollapsed collapses or expands the group under the cursor.
+func (m *model) setGroupCollapsed(collapsed bool) {
+	m.cursor = grouping.SetCollapsed(m.groupBy, m.tableRows(), m.cursor, m.collapsed, collapsed)
+}
+
+// renderGroupHeader renders a group header row with its session count,
+// per-state counts and token total.
+func (m model) renderGroupHeader(g *grouping.Group, isSelected bool, width int) string {
+	var b strings.Builder
+
+	if isSelected {
+		b.WriteString(selectedStyle.Render(" >"))
+	} else {
+		b.WriteString("  ")
+	}
+
+	arrow := "\u25BE"
+	if m.collapsed.Has(m.groupBy, g.Key) {
+		arrow = "\u25B8"
+	}
+
+	var counts [session.StateExited + 1]int
+	tokens := 0
+	for _, s := range g.Sessions {
+		counts[s.State]++
+		tokens += s.Usage.Total()
+	}
+
+	label := fmt.Sprintf(" %s %s (%d)", arrow, g.Key, len(g.Sessions))
+	var parts []headerPart
+	stateStyles := []struct {
+		state session.State
+		style func(...string) string
+	}{
+		{session.StateActive, activeStyle.Render},
+		{session.StateWaiting, waitingStyle.Render},
+		{session.StateInput, inputStyle.Render},
+		{session.StateIdle, dimStyle.Render},
+		{session.StateExited, exitedStyle.Render},
+	}
+	for _, entry := range stateStyles {
+		if counts[entry.state] > 0 {
+			text := fmt.Sprintf("%d %s", counts[entry.state], entry.state)
+			parts = append(parts, headerPart{text, entry.style(text)})
+		}
+	}
+	if tokens > 0 {
+		text := session.FormatTokens(tokens) + " tokens"
+		parts = append(parts, headerPart{text, dimStyle.Render(text)})
+	}
+
+	summaryLen := 0
+	var styled []string
+	for _, part := range parts {
+		summaryLen += len(part.plain) + 2
+		styled = append(styled, part.styled)
+	}
+
+	label = truncateString(label, max(width-2-summaryLen, minProjectColWidth))
+	b.WriteString(groupHeaderStyle.Render(label))
+	b.WriteString("  ")
+	b.WriteString(strings.Join(styled, "  "))
+
+	return b.String()
+}
@@ -0,0 +1,442 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// HistorySort represents the available orderings of the session history.
+type HistorySort int
+
+const (
+	HistoryByModified HistorySort = iota // most recently modified first
+	HistoryByCreated                     // most recently created first
+	HistoryByMessages                    // most messages first
+	HistoryBySize                        // largest transcript first
+	HistoryByProject                     // alphabetical
+)
+
+const (
+	// historyTimeColWidth is the width of the MODIFIED and CREATED columns.
+	historyTimeColWidth = 16
+
+	// historyWideThreshold is the terminal width above which the CREATED and
+	// BRANCH columns appear in the history view.
+	historyWideThreshold = 130
+
+	// historyTimeFormat is the timestamp layout used in the history view.
+	historyTimeFormat = "2006-01-02 15:04"
+)
+
+// historyLoadedMsg carries the result of a background history scan.
+type historyLoadedMsg struct {
+	entries []session.HistoryEntry
+}
+
+// loadHistoryCmd scans all transcripts in claudeDirs in a background
+// goroutine.
+func loadHistoryCmd(claudeDirs []string) tea.Cmd {
+	return func() tea.Msg {
+		return historyLoadedMsg{entries: session.ScanHistory(claudeDirs...)}
+	}
+}
+
+// openHistory switches to the history view and starts a fresh scan.
+func (m model) openHistory() (tea.Model, tea.Cmd) {
+	m.mode = ModeHistory
+	m.historyCursor = 0
+	return m, loadHistoryCmd(session.ConfigDirs(m.claudeDirs))
+}
+
+func (m model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	filtered := m.filteredHistory()
+
+	switch msg.String() {
+	case "q":
+		if m.historyOnly {
+			return m, tea.Quit
+		}
+		m.mode = ModeNormal
+	case "esc", "H":
+		if !m.historyOnly {
+			m.mode = ModeNormal
+		}
+	case "j", "down":
+		if m.historyCursor < len(filtered)-1 {
+			m.historyCursor++
+		}
+	case "k", "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "g", "home":
+		m.historyCursor = 0
+	case "G", "end":
+		m.historyCursor = max(len(filtered)-1, 0)
+	case "enter":
+		if len(filtered) > 0 {
+			m.mode = ModeHistoryDetail
+		}
+	case "/":
+		m.mode = ModeHistorySearch
+		m.historyInput.SetValue(m.historyQuery)
+		cmd := m.historyInput.Focus()
+		return m, cmd
+	case "s":
+		m.historySort = (m.historySort + 1) % 5
+	case "c":
+		if entry, ok := m.selectedHistoryEntry(); ok {
+			return m, copyResumeCmd(historySession(entry))
+		}
+	case "o":
+		if entry, ok := m.selectedHistoryEntry(); ok {
+			return m, openResumeCmd(historySession(entry))
+		}
+	}
+
+	return m, nil
+}
+
+// historyLeaveHelp returns the hint for leaving the history browser: back
+// to the monitor, or quitting when cctop started in the browser.
+func (m model) historyLeaveHelp() string {
+	if m.historyOnly {
+		return "q: quit"
+	}
+	return "esc: back"
+}
+
+func (m model) updateHistorySearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.historyQuery = m.historyInput.Value()
+		m.historyInput.Blur()
+		m.mode = ModeHistory
+		m.historyCursor = 0
+		return m, nil
+	case "esc":
+		m.historyInput.Blur()
+		m.mode = ModeHistory
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.historyInput, cmd = m.historyInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m model) updateHistoryDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.mode = ModeHistory
+	case "c":
+		if entry, ok := m.selectedHistoryEntry(); ok {
+			return m, copyResumeCmd(historySession(entry))
+		}
+	case "o":
+		if entry, ok := m.selectedHistoryEntry(); ok {
+			return m, openResumeCmd(historySession(entry))
+		}
+	}
+	return m, nil
+}
+
+// filteredHistory returns history entries matching the search query, sorted
+// by the current history sort. A query keeps MatchHistory's relevance order
+// only when sorting by modification time.
+func (m model) filteredHistory() []session.HistoryEntry {
+	entries := m.history
+	if m.historyQuery != "" {
+		entries = session.MatchHistory(entries, m.historyQuery)
+		if m.historySort == HistoryByModified {
+			return entries
+		}
+	}
+
+	sorted := make([]session.HistoryEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch m.historySort {
+		case HistoryByCreated:
+			return sorted[i].Created.After(sorted[j].Created)
+		case HistoryByMessages:
+			return sorted[i].Messages > sorted[j].Messages
+		case HistoryBySize:
+			return sorted[i].Size > sorted[j].Size
+		case HistoryByProject:
+			return sorted[i].Project < sorted[j].Project
+		default: // HistoryByModified
+			return sorted[i].Modified.After(sorted[j].Modified)
+		}
+	})
+
+	return sorted
+}
+
+// selectedHistoryEntry returns the history entry under the cursor, if any.
+func (m model) selectedHistoryEntry() (session.HistoryEntry, bool) {
+	filtered := m.filteredHistory()
+	if m.historyCursor < 0 || m.historyCursor >= len(filtered) {
+		return session.HistoryEntry{}, false
+	}
+	return filtered[m.historyCursor], true
+}
+
+// historySession adapts a history entry for the session resume actions.
+func historySession(entry session.HistoryEntry) session.Session {
+	return session.Session{
+		CWD:            entry.CWD,
+		State:          session.StateExited,
+		Project:        entry.Project,
+		Topic:          entry.Topic,
+		Branch:         entry.Branch,
+		Messages:       entry.Messages,
+		SessionID:      entry.SessionID,
+		TranscriptPath: entry.Path,
+		LastActivity:   entry.Modified,
+	}
+}
+
+// renderHistory renders the session history browser.
+func (m model) renderHistory() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+	height := m.windowHeight
+	if height == 0 {
+		height = 24
+	}
+
+	filtered := m.filteredHistory()
+
+	// ---- Header ----
+	titleText := " cctop -- Session History"
+	countText := fmt.Sprintf("%d sessions", len(m.history))
+	if m.historyQuery != "" {
+		countText = fmt.Sprintf("%d/%d sessions", len(filtered), len(m.history))
+	}
+	middlePad := width - len(titleText) - len(countText) - 1
+	if middlePad < 1 {
+		middlePad = 1
+	}
+	b.WriteString(headerStyle.Width(width).Render(titleText + strings.Repeat(" ", middlePad) + countText))
+	b.WriteString("\n")
+
+	if !m.historyLoaded {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("  Scanning transcripts..."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	if len(filtered) == 0 {
+		b.WriteString("\n")
+		if m.historyQuery != "" {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  No sessions match %q", m.historyQuery)))
+		} else {
+			b.WriteString(dimStyle.Render("  No transcripts found"))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  /: search  " + m.historyLeaveHelp()))
+		return b.String()
+	}
+
+	// ---- Column widths ----
+	const msgsWidth, sizeWidth, branchWidth = 5, 6, 16
+	showWide := width > historyWideThreshold
+	remaining := width - historyTimeColWidth - msgsWidth - sizeWidth - fixedColumnSpacing - 2
+	if showWide {
+		remaining -= historyTimeColWidth + branchWidth + 2
+	}
+	projectWidth := max(remaining*projectWidthPercent/100, minProjectColWidth)
+	topicWidth := max(remaining-projectWidth, minTopicColWidth)
+
+	b.WriteString("\n")
+
+	// ---- Column headers ----
+	b.WriteString("  ")
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, "MODIFIED")))
+	if showWide {
+		b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, "CREATED")))
+	}
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", projectWidth, "PROJECT")))
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", topicWidth, "FIRST PROMPT")))
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %*s", msgsWidth, "MSGS")))
+	if showWide {
+		b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", branchWidth, "BRANCH")))
+	}
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %*s", sizeWidth, "SIZE")))
+	b.WriteString("\n")
+
+	// ---- Rows (scrolled to keep the cursor visible) ----
+	maxRows := max(height-uiVerticalOverhead, 1)
+	offset := 0
+	if m.historyCursor >= maxRows {
+		offset = m.historyCursor - maxRows + 1
+	}
+
+	for i := offset; i < len(filtered) && i < offset+maxRows; i++ {
+		entry := filtered[i]
+
+		if i == m.historyCursor {
+			b.WriteString(selectedStyle.Render(" >"))
+		} else {
+			b.WriteString("  ")
+		}
+
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, formatHistoryTime(entry.Modified))))
+		if showWide {
+			b.WriteString(dimStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, formatHistoryTime(entry.Created))))
+		}
+		b.WriteString(fmt.Sprintf(" %-*s", projectWidth, truncateString(entry.Project, projectWidth)))
+		b.WriteString(fmt.Sprintf(" %-*s", topicWidth, truncateString(entry.Topic, topicWidth)))
+		b.WriteString(fmt.Sprintf(" %*d", msgsWidth, entry.Messages))
+		if showWide {
+			b.WriteString(fmt.Sprintf(" %-*s", branchWidth, truncateString(entry.Branch, branchWidth)))
+		}
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %*s", sizeWidth, session.FormatSize(entry.Size))))
+		b.WriteString("\n")
+	}
+
+	if hidden := len(filtered) - offset - maxRows; hidden > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more sessions", hidden)))
+		b.WriteString("\n")
+	}
+
+	// ---- Search indicator ----
+	if m.historyQuery != "" {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("  search: %q | %d/%d shown", m.historyQuery, len(filtered), len(m.history))))
+		b.WriteString("\n")
+	}
+
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
+	// ---- Help line ----
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  j/k: navigate  enter: detail  /: search  s: sort(%s)  c: copy resume  o: open resume  %s", historySortName(m.historySort), m.historyLeaveHelp())))
+
+	return b.String()
+}
+
+// renderHistorySearch renders the history search input view.
+func (m model) renderHistorySearch() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+
+	b.WriteString(headerStyle.Width(width).Render(" cctop -- Session History"))
+	b.WriteString("\n\n")
+	b.WriteString(filterPromptStyle.Render("  Search: "))
+	b.WriteString(m.historyInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("  enter: apply  esc: cancel"))
+
+	return b.String()
+}
+
+// renderHistoryDetail renders the expanded view of the selected history entry.
+func (m model) renderHistoryDetail() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+
+	b.WriteString(headerStyle.Width(width).Render(" cctop -- Session Detail"))
+	b.WriteString("\n\n")
+
+	entry, ok := m.selectedHistoryEntry()
+	if !ok {
+		b.WriteString("  No session selected\n")
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("  esc: back"))
+		return b.String()
+	}
+
+	details := []struct {
+		label string
+		value string
+	}{
+		{"Project", entry.Project},
+		{"CWD", entry.CWD},
+		{"Branch", entry.Branch},
+		{"Created", formatHistoryTime(entry.Created)},
+		{"Modified", formatHistoryTime(entry.Modified)},
+		{"Messages", fmt.Sprintf("%d", entry.Messages)},
+		{"Size", session.FormatSize(entry.Size)},
+		{"Session", entry.SessionID},
+		{"Path", entry.Path},
+		{"Resume", entry.ResumeCommand()},
+		{"Topic", entry.Topic},
+	}
+
+	for _, detail := range details {
+		if detail.value == "" || detail.value == "0" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s  %s\n", detailLabelStyle.Render(fmt.Sprintf("%-10s", detail.label)), detail.value))
+	}
+
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  c: copy resume  o: open resume  esc: back"))
+
+	return b.String()
+}
+
+// formatHistoryTime formats a timestamp for the history view, or returns an
+// empty string for the zero time.
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(historyTimeFormat)
+}
+
+// historySortName returns the display name for the current history sort.
+func historySortName(field HistorySort) string {
+	switch field {
+	case HistoryByCreated:
+		return "created"
+	case HistoryByMessages:
+		return "messages"
+	case HistoryBySize:
+		return "size"
+	case HistoryByProject:
+		return "project"
+	default:
+		return "modified"
+	}
+}
+
+// ParseHistorySort converts a sort name (as shown in the help line) to a
+// HistorySort.
+func ParseHistorySort(name string) (HistorySort, error) {
+	for field := HistoryByModified; field <= HistoryByProject; field++ {
+		if historySortName(field) == name {
+			return field, nil
+		}
+	}
+	return HistoryByModified, fmt.Errorf("unknown sort %q (want modified, created, messages, size or project)", name)
+}
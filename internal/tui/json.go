@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// runJSON writes filtered sessions as JSON snapshots to stdout. With
+// Options.Once a single snapshot is written; otherwise one snapshot per
+// refresh interval is streamed, one object per line, until interrupted.
+func runJSON(opts Options, remotes remoteSources) error {
+	m := newModel(opts)
+	if remotes != nil {
+		remotes.WaitReady(remoteWaitTimeout)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	hostname, _ := os.Hostname()
+
+	for {
+		m.sessions, _, _ = discoverSessions(m.discoverOptions(), remotes, m.sampler)
+		m.trackStates(time.Now())
+
+		snapshot := session.Snapshot{
+			GeneratedAt: time.Now(),
+			Hostname:    hostname,
+			Sessions:    m.filteredSessions(),
+		}
+		if snapshot.Sessions == nil {
+			snapshot.Sessions = []session.Session{}
+		}
+		if err := encoder.Encode(snapshot); err != nil {
+			return err
+		}
+
+		if opts.Once {
+			return nil
+		}
+		time.Sleep(refreshInterval)
+	}
+}
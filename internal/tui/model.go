@@ -5,11 +5,19 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/Jevs21/cctop/internal/activity"
+	"github.com/Jevs21/cctop/internal/config"
+	"github.com/Jevs21/cctop/internal/grouping"
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/query"
+	"github.com/Jevs21/cctop/internal/search"
 	"github.com/Jevs21/cctop/internal/session"
 )
 
@@ -20,15 +28,14 @@ const (
 	ModeNormal Mode = iota
 	ModeFilter
 	ModeDetail
-)
-
-// SortField represents the available sort orderings.
-type SortField int
-
-const (
-	SortByState    SortField = iota // active > waiting > idle
-	SortByDuration                  // longest first
-	SortByProject                   // alphabetical
+	ModeReply
+	ModeConfirm
+	ModeHistory
+	ModeHistorySearch
+	ModeHistoryDetail
+	ModeSearch
+	ModeSearchResults
+	ModeTree
 )
 
 // StateFilter represents which session states to display.
@@ -40,15 +47,13 @@ const (
 	FilterWaiting
 	FilterInput
 	FilterIdle
+	FilterExited
 )
 
 const (
 	// minTerminalWidth is the minimum terminal width before showing a "too narrow" message.
 	minTerminalWidth = 60
 
-	// branchColThreshold is the remaining width above which the BRANCH column appears.
-	branchColThreshold = 80
-
 	// projectWidthPercent is the percentage of remaining width allocated to the PROJECT column.
 	projectWidthPercent = 35
 
@@ -62,22 +67,19 @@ const (
 	fixedColumnSpacing = 6
 
 	// uiVerticalOverhead is the number of lines consumed by header, blank, column header,
-	// help line, and margins.
-	uiVerticalOverhead = 6
+	// help lines, and margins.
+	uiVerticalOverhead = 7
 
 	// refreshInterval is the time between session discovery cycles.
 	refreshInterval = 1 * time.Second
-)
 
-// columnWidths holds the computed widths for each table column.
-type columnWidths struct {
-	state   int
-	source  int
-	project int
-	topic   int
-	branch  int
-	dur     int
-}
+	// statusDisplayDuration is how long an action result stays in the status line.
+	statusDisplayDuration = 5 * time.Second
+
+	// defaultRecentWindow is how far back recent mode looks for exited
+	// sessions when no --recent window is given.
+	defaultRecentWindow = 30 * time.Minute
+)
 
 // headerPart pairs the plain text of a header element with its styled rendering.
 type headerPart struct {
@@ -92,8 +94,52 @@ type model struct {
 	mode         Mode
 	filterInput  textinput.Model
 	filterText   string
+	filterQuery  *query.Query // Parsed filterText
+	filterErr    error        // Parse error for the live filter input
+	replyInput   textinput.Model
+	replyTarget  session.Session
+	confirm      confirmation
+	status       statusLine
+	marked       map[int]bool // PIDs selected with space for bulk actions
 	stateFilter  StateFilter
-	sortField    SortField
+	sortKeys     []sortKey
+	columns      []string      // Visible column IDs in display order
+	views        []config.View // Saved views, selected with 1-9
+	activeView   int           // Index into views, or -1 for the default view
+	groupBy      grouping.By
+	collapsed    grouping.Collapsed     // Collapsed groups of every grouping
+	stateSince   map[string]stateChange // When each session entered its current state
+	activity     *activity.Tracker      // Per-minute transcript growth, keyed by sessionKey
+	remotes      remoteSources          // Remote hosts from --host and --hub; nil when local only
+	showHosts    bool                   // Add the HOST column to the default columns
+	allUsers     bool                   // List other users' sessions and add the USER column
+	now          time.Time              // Time of the last refresh
+	showRecent   bool                   // Also list recently exited sessions
+	recentWindow time.Duration          // How far back recent mode looks
+	claudeDirs   []string               // Config directories searched besides the default
+	refreshGen   int                    // Bumped when discovery options change; older refreshes are dropped
+
+	sampler    *process.Sampler // Shared by copies of the model, so CPU% spans refreshes
+	procs      process.Table    // Process sample of the latest refresh
+	procsErr   error            // Why processes could not be listed
+	treeOffset int              // First line shown in the process tree view
+
+	history       []session.HistoryEntry
+	historyLoaded bool
+	historyCursor int
+	historyInput  textinput.Model
+	historyQuery  string
+	historySort   HistorySort
+	historyOnly   bool // Started in the history browser (cctop sessions), so leaving it quits
+
+	searchInput   textinput.Model
+	searchQuery   string
+	searchResults []search.Result
+	searchCursor  int
+	searchErr     error
+	searching     bool
+	searchCache   *search.Cache // Opened on first search, reused afterwards
+
 	windowWidth  int
 	windowHeight int
 	onceMode     bool
@@ -103,21 +149,75 @@ type model struct {
 
 // sessionsRefreshedMsg carries newly discovered sessions from a background refresh.
 type sessionsRefreshedMsg struct {
+	gen      int // refreshGen the refresh was started under
 	sessions []session.Session
+	procs    process.Table // Process sample the sessions' CPU and memory come from
+	procsErr error
 }
 
-// tickMsg triggers a periodic session refresh.
-type tickMsg time.Time
+// tickMsg triggers a periodic session refresh. It carries the refreshGen of
+// the refresh loop that scheduled it.
+type tickMsg int
+
+// Options configures the TUI from command-line flags.
+type Options struct {
+	Once         bool          // Print once and exit
+	Debug        bool          // Print timing diagnostics to stderr
+	RecentWindow time.Duration // When positive, start in recent mode with this window
+	Filter       string        // Initial filter query (see the query package)
+	Format       string        // Output format: "table" (default) or "json"
+	Sort         []string      // Sort keys, e.g. ["state", "-in_state"]; overrides the view's
+	Views        []config.View // Saved views from the config file
+	View         string        // Name of the view to start in
+	Group        grouping.By   // Grouping; overrides the view's
+
+	Hosts         []string // ssh targets whose sessions are merged into the table
+	RemoteCommand string   // Command that runs cctop on remote hosts (default "cctop")
+	ClaudeDirs    []string // Config directories searched besides the default
+	AllUsers      bool     // Also list other users' sessions
+	Hub           string   // URL of a cctop hub whose sessions are merged into the table
+	Token         string   // Shared token for the hub
+
+	History      bool        // Start in the session history browser
+	HistoryQuery string      // Initial history search query
+	HistorySort  HistorySort // Initial history sort order
+}
+
+// Run starts the Bubbletea TUI. Options.Once prints once and exits;
+// Options.Debug enables timing diagnostics.
+func Run(opts Options) error {
+	if _, err := query.Parse(opts.Filter); err != nil {
+		return fmt.Errorf("invalid filter %q: %w", opts.Filter, err)
+	}
+	if _, err := parseSortKeys(opts.Sort); err != nil {
+		return err
+	}
+	if err := validateViews(opts.Views); err != nil {
+		return err
+	}
+	if opts.View != "" && viewIndex(opts.Views, opts.View) == -1 {
+		return fmt.Errorf("no view named %q in the config file", opts.View)
+	}
+
+	remotes := startRemotes(opts)
+	if remotes != nil {
+		defer remotes.Close()
+	}
+
+	if opts.Format == "json" {
+		return runJSON(opts, remotes)
+	}
 
-// Run starts the Bubbletea TUI. onceMode prints once and exits; debugMode
-// enables timing diagnostics.
-func Run(onceMode bool, debugMode bool) error {
 	// --once mode: bypass Bubbletea entirely, print to stdout directly
-	if onceMode {
-		return runOnce(debugMode)
+	if opts.Once {
+		return runOnce(opts, remotes)
 	}
 
-	initialModel := newModel(false, debugMode)
+	initialModel := newModel(opts)
+	initialModel.remotes = remotes
+	if opts.History {
+		initialModel.mode = ModeHistory
+	}
 	program := tea.NewProgram(initialModel, tea.WithAltScreen())
 	_, err := program.Run()
 	return err
@@ -125,62 +225,156 @@ func Run(onceMode bool, debugMode bool) error {
 
 // runOnce discovers sessions and prints the table once to stdout without
 // requiring a TTY or alternate screen.
-func runOnce(debugMode bool) error {
+func runOnce(opts Options, remotes remoteSources) error {
 	var debugStart time.Time
-	if debugMode {
+	if opts.Debug {
 		debugStart = time.Now()
 	}
 
-	sessions := session.DiscoverAll()
+	m := newModel(opts)
+
+	if opts.History {
+		m.history = session.ScanHistory(session.ConfigDirs(opts.ClaudeDirs)...)
+		m.historyLoaded = true
+		m.windowWidth = 160
+		m.windowHeight = len(m.history) + uiVerticalOverhead + 1
+		fmt.Println(m.renderHistory())
+		return nil
+	}
+
+	if remotes != nil {
+		remotes.WaitReady(remoteWaitTimeout)
+		m.remotes = remotes
+	}
+	sessions, _, _ := discoverSessions(m.discoverOptions(), remotes, m.sampler)
 
-	if debugMode {
+	if opts.Debug {
 		fmt.Fprintf(os.Stderr, "[debug] discovery: %dms, sessions: %d\n",
 			time.Since(debugStart).Milliseconds(), len(sessions))
 	}
 
-	m := newModel(true, debugMode)
 	m.sessions = sessions
-	m.firstRefresh = true
-	m.windowWidth = 120
-	m.windowHeight = 40
-
-	fmt.Println(m.renderNormal())
+	fmt.Println(m.renderOnce(120, 40))
 	return nil
 }
 
-func newModel(onceMode bool, debugMode bool) model {
+// Render returns the session table as --once prints it, laid out for a
+// terminal width columns wide and tall enough for every session.
+func Render(opts Options, sessions []session.Session, width int) string {
+	m := newModel(opts)
+	m.sessions = sessions
+	return m.renderOnce(width, len(sessions)+uiVerticalOverhead+2)
+}
+
+// renderOnce renders the table of the model's sessions at the given size.
+func (m model) renderOnce(width, height int) string {
+	m.firstRefresh = true
+	m.trackStates(time.Now())
+	m.windowWidth = width
+	m.windowHeight = height
+	return m.renderNormal()
+}
+
+func newModel(opts Options) model {
 	filterInput := textinput.New()
-	filterInput.Placeholder = "filter sessions..."
-	filterInput.CharLimit = 100
+	filterInput.Placeholder = "state:waiting project:api* -topic:refactor"
+	filterInput.CharLimit = 200
 	filterInput.Width = 40
 
-	return model{
-		onceMode:     onceMode,
-		debugMode:    debugMode,
+	replyInput := textinput.New()
+	replyInput.Placeholder = "reply to session..."
+	replyInput.CharLimit = 2000
+	replyInput.Width = 60
+
+	historyInput := textinput.New()
+	historyInput.Placeholder = "search history..."
+	historyInput.CharLimit = 100
+	historyInput.Width = 40
+
+	searchInput := textinput.New()
+	searchInput.Placeholder = "search all transcripts..."
+	searchInput.CharLimit = 200
+	searchInput.Width = 50
+
+	recentWindow := opts.RecentWindow
+	if recentWindow <= 0 {
+		recentWindow = defaultRecentWindow
+	}
+
+	m := model{
+		onceMode:     opts.Once,
+		debugMode:    opts.Debug,
 		filterInput:  filterInput,
-		sortField:    SortByState,
+		replyInput:   replyInput,
+		marked:       make(map[int]bool),
 		stateFilter:  FilterAll,
+		views:        opts.Views,
+		activeView:   -1,
+		stateSince:   make(map[string]stateChange),
+		activity:     &activity.Tracker{},
+		collapsed:    make(grouping.Collapsed),
+		showRecent:   opts.RecentWindow > 0,
+		recentWindow: recentWindow,
+		claudeDirs:   opts.ClaudeDirs,
+		historyInput: historyInput,
+		historyQuery: opts.HistoryQuery,
+		historySort:  opts.HistorySort,
+		historyOnly:  opts.History,
+		searchInput:  searchInput,
+		showHosts:    len(opts.Hosts) > 0 || opts.Hub != "",
+		allUsers:     opts.AllUsers,
+		sampler:      process.NewSampler(),
 		firstRefresh: false,
 	}
+
+	// Run validates the options up front, so errors cannot occur here
+	m.applyView(viewIndex(opts.Views, opts.View))
+	if opts.Filter != "" {
+		m.filterText = opts.Filter
+		m.filterQuery, _ = query.Parse(opts.Filter)
+	}
+	if len(opts.Sort) > 0 {
+		m.sortKeys, _ = parseSortKeys(opts.Sort)
+	}
+	if opts.Group != grouping.None {
+		m.groupBy = opts.Group
+	}
+
+	return m
 }
 
-// Init returns the initial commands: an immediate refresh and a tick timer.
+// Init returns the initial commands: an immediate refresh, which starts the
+// tick loop, plus a history scan when starting in the history browser.
 func (m model) Init() tea.Cmd {
-	return tea.Batch(refreshSessionsCmd(), tickCmd())
+	if m.mode == ModeHistory {
+		return tea.Batch(m.refreshCmd(), loadHistoryCmd(session.ConfigDirs(m.claudeDirs)))
+	}
+	return m.refreshCmd()
 }
 
-// refreshSessionsCmd runs session discovery in a background goroutine.
-func refreshSessionsCmd() tea.Cmd {
+// discoverOptions returns the discovery options for the current view.
+func (m model) discoverOptions() session.DiscoverOptions {
+	opts := session.DiscoverOptions{ClaudeDirs: m.claudeDirs, AllUsers: m.allUsers}
+	if m.showRecent {
+		opts.RecentWindow = m.recentWindow
+	}
+	return opts
+}
+
+// refreshCmd runs session discovery in a background goroutine. Its result
+// schedules the next tick, so exactly one refresh loop runs per generation.
+func (m model) refreshCmd() tea.Cmd {
+	gen, opts, remotes, sampler := m.refreshGen, m.discoverOptions(), m.remotes, m.sampler
 	return func() tea.Msg {
-		sessions := session.DiscoverAll()
-		return sessionsRefreshedMsg{sessions: sessions}
+		sessions, procs, err := discoverSessions(opts, remotes, sampler)
+		return sessionsRefreshedMsg{gen: gen, sessions: sessions, procs: procs, procsErr: err}
 	}
 }
 
-// tickCmd schedules the next refresh after the interval.
-func tickCmd() tea.Cmd {
-	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+// tickCmd schedules the next refresh of generation gen after the interval.
+func tickCmd(gen int) tea.Cmd {
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return tickMsg(gen)
 	})
 }
 
@@ -193,17 +387,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case sessionsRefreshedMsg:
+		// A refresh started before the options changed is stale, and its
+		// loop ends here; the refresh that replaced it keeps ticking
+		if msg.gen != m.refreshGen {
+			return m, nil
+		}
 		m.sessions = msg.sessions
+		m.procs, m.procsErr = msg.procs, msg.procsErr
 		m.firstRefresh = true
+		m.pruneMarked()
+		m.trackStates(time.Now())
+		m.trackActivity(m.now)
+		m.cursor = min(m.cursor, max(len(m.tableRows())-1, 0))
 
 		// In --once mode, quit after the first refresh
 		if m.onceMode {
 			return m, tea.Quit
 		}
-		return m, tickCmd()
+		return m, tickCmd(m.refreshGen)
 
 	case tickMsg:
-		return m, refreshSessionsCmd()
+		if int(msg) != m.refreshGen {
+			return m, nil
+		}
+		return m, m.refreshCmd()
+
+	case historyLoadedMsg:
+		m.history = msg.entries
+		m.historyLoaded = true
+		return m, nil
+
+	case searchResultsMsg:
+		m.searchCache = msg.cache
+		m.searching = false
+		if msg.query != m.searchQuery {
+			// The query changed while searching: run the latest one
+			m.searching = true
+			return m, searchCmd(m.searchCache, session.ConfigDirs(m.claudeDirs), m.searchQuery)
+		}
+		m.searchResults = msg.results
+		m.searchErr = msg.err
+		return m, nil
+
+	case actionResultMsg:
+		m.status = statusLine{text: msg.text, isError: msg.isError, at: time.Now(), clipboard: msg.clipboard}
+		return m, nil
 
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
@@ -217,6 +445,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateFilter(msg)
 		case ModeDetail:
 			return m.updateDetail(msg)
+		case ModeReply:
+			return m.updateReply(msg)
+		case ModeConfirm:
+			return m.updateConfirm(msg)
+		case ModeHistory:
+			return m.updateHistory(msg)
+		case ModeHistorySearch:
+			return m.updateHistorySearch(msg)
+		case ModeHistoryDetail:
+			return m.updateHistoryDetail(msg)
+		case ModeSearch:
+			return m.updateSearch(msg)
+		case ModeSearchResults:
+			return m.updateSearchResults(msg)
+		case ModeTree:
+			return m.updateTree(msg)
 		}
 	}
 
@@ -224,13 +468,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	filtered := m.filteredSessions()
+	rows := m.tableRows()
 
 	switch msg.String() {
 	case "q":
 		return m, tea.Quit
 	case "j", "down":
-		if m.cursor < len(filtered)-1 {
+		if m.cursor < len(rows)-1 {
 			m.cursor++
 		}
 	case "k", "up":
@@ -238,19 +482,79 @@ func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.cursor--
 		}
 	case "enter":
-		if len(filtered) > 0 {
-			m.mode = ModeDetail
+		if row, ok := m.selectedRow(); ok {
+			if row.IsHeader() {
+				m.setGroupCollapsed(!m.collapsed.Has(m.groupBy, row.Group.Key))
+			} else {
+				m.mode = ModeDetail
+			}
 		}
+	case "g":
+		m.groupBy = (m.groupBy + 1) % grouping.Count
+		m.cursor = 0
+	case "left", "h":
+		m.setGroupCollapsed(true)
+	case "right", "l":
+		m.setGroupCollapsed(false)
 	case "/":
 		m.mode = ModeFilter
 		m.filterInput.SetValue(m.filterText)
 		cmd := m.filterInput.Focus()
 		return m, cmd
 	case "f":
-		m.stateFilter = (m.stateFilter + 1) % 5
+		m.stateFilter = (m.stateFilter + 1) % 6
+		m.cursor = 0
+	case "H":
+		return m.openHistory()
+	case "F":
+		return m.openSearch()
+	case "t":
+		return m.openTree()
+	case "R":
+		m.showRecent = !m.showRecent
 		m.cursor = 0
+		m.refreshGen++
+		return m, m.refreshCmd()
 	case "s":
-		m.sortField = (m.sortField + 1) % 3
+		m.cycleSortColumn()
+	case "S":
+		m.toggleSortDirection()
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		index := int(msg.String()[0]-'0') - 1
+		if index < len(m.views) {
+			m.applyView(index)
+			m.cursor = 0
+		}
+	case "r":
+		return m.startReply()
+	case " ":
+		row, ok := m.selectedRow()
+		switch {
+		case ok && row.IsHeader():
+			grouping.ToggleMarks(row.Group, m.marked, markable)
+		case ok && markable(row.Session):
+			m.marked[row.Session.PID] = !m.marked[row.Session.PID]
+			if !m.marked[row.Session.PID] {
+				delete(m.marked, row.Session.PID)
+			}
+		default:
+			return m, nil
+		}
+		if m.cursor < len(rows)-1 {
+			m.cursor++
+		}
+	case "x":
+		return m, signalSessionsCmd(m.actionTargets(), syscall.SIGINT)
+	case "K":
+		return m.confirmTerminate()
+	case "c":
+		if selected, ok := m.selectedSession(); ok {
+			return m, copyResumeCmd(selected)
+		}
+	case "o":
+		if selected, ok := m.selectedSession(); ok {
+			return m, openResumeCmd(selected)
+		}
 	}
 
 	return m, nil
@@ -259,18 +563,28 @@ func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		m.filterText = m.filterInput.Value()
+		parsed, err := query.Parse(m.filterInput.Value())
+		if err != nil {
+			// Stay in the input so the query can be corrected
+			m.filterErr = err
+			return m, nil
+		}
+		m.filterText = strings.TrimSpace(m.filterInput.Value())
+		m.filterQuery = parsed
+		m.filterErr = nil
 		m.filterInput.Blur()
 		m.mode = ModeNormal
 		m.cursor = 0
 		return m, nil
 	case "esc":
+		m.filterErr = nil
 		m.filterInput.Blur()
 		m.mode = ModeNormal
 		return m, nil
 	default:
 		var cmd tea.Cmd
 		m.filterInput, cmd = m.filterInput.Update(msg)
+		_, m.filterErr = query.Parse(m.filterInput.Value())
 		return m, cmd
 	}
 }
@@ -279,10 +593,29 @@ func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
 		m.mode = ModeNormal
+	case "r":
+		return m.startReply()
+	case "c":
+		if selected, ok := m.selectedSession(); ok {
+			return m, copyResumeCmd(selected)
+		}
+	case "o":
+		if selected, ok := m.selectedSession(); ok {
+			return m, openResumeCmd(selected)
+		}
 	}
 	return m, nil
 }
 
+// selectedSession returns the session under the cursor, if any.
+func (m model) selectedSession() (session.Session, bool) {
+	row, ok := m.selectedRow()
+	if !ok || row.IsHeader() {
+		return session.Session{}, false
+	}
+	return row.Session, true
+}
+
 // filteredSessions returns sessions matching the current filter and state filter,
 // sorted by the current sort field.
 func (m model) filteredSessions() []session.Session {
@@ -310,32 +643,23 @@ func (m model) filteredSessions() []session.Session {
 			if s.State != session.StateIdle {
 				continue
 			}
-		}
-
-		// Apply text filter
-		if m.filterText != "" {
-			lowerFilter := strings.ToLower(m.filterText)
-			matchesProject := strings.Contains(strings.ToLower(s.Project), lowerFilter)
-			matchesTopic := strings.Contains(strings.ToLower(s.Topic), lowerFilter)
-			matchesBranch := strings.Contains(strings.ToLower(s.Branch), lowerFilter)
-			if !matchesProject && !matchesTopic && !matchesBranch {
+		case FilterExited:
+			if s.State != session.StateExited {
 				continue
 			}
 		}
 
+		// Apply query filter
+		if !m.filterQuery.Match(s) {
+			continue
+		}
+
 		filtered = append(filtered, s)
 	}
 
 	// Sort
 	sort.SliceStable(filtered, func(i, j int) bool {
-		switch m.sortField {
-		case SortByDuration:
-			return filtered[i].Duration > filtered[j].Duration
-		case SortByProject:
-			return filtered[i].Project < filtered[j].Project
-		default: // SortByState
-			return filtered[i].State.Priority() < filtered[j].State.Priority()
-		}
+		return m.compareSessions(filtered[i], filtered[j]) < 0
 	})
 
 	return filtered
@@ -352,38 +676,27 @@ func (m model) View() string {
 		return m.renderFilter()
 	case ModeDetail:
 		return m.renderDetail()
+	case ModeReply:
+		return m.renderReply()
+	case ModeConfirm:
+		return m.renderConfirm()
+	case ModeHistory:
+		return m.renderHistory()
+	case ModeHistorySearch:
+		return m.renderHistorySearch()
+	case ModeHistoryDetail:
+		return m.renderHistoryDetail()
+	case ModeSearch:
+		return m.renderSearch()
+	case ModeSearchResults:
+		return m.renderSearchResults()
+	case ModeTree:
+		return m.renderTree()
 	default:
 		return m.renderNormal()
 	}
 }
 
-// computeColumnWidths calculates column widths based on the terminal width.
-func computeColumnWidths(terminalWidth int) columnWidths {
-	cw := columnWidths{
-		state:  3,
-		source: 7,
-		dur:    7,
-	}
-
-	remaining := terminalWidth - cw.state - cw.source - cw.dur - fixedColumnSpacing
-	if remaining > branchColThreshold {
-		cw.branch = 16
-		remaining -= cw.branch + 2
-	}
-
-	cw.project = remaining * projectWidthPercent / 100
-	cw.topic = remaining - cw.project
-
-	if cw.project < minProjectColWidth {
-		cw.project = minProjectColWidth
-	}
-	if cw.topic < minTopicColWidth {
-		cw.topic = minTopicColWidth
-	}
-
-	return cw
-}
-
 // stateIconStyled renders a state icon with the appropriate style and column width.
 func stateIconStyled(state session.State, colWidth int) string {
 	switch state {
@@ -393,6 +706,8 @@ func stateIconStyled(state session.State, colWidth int) string {
 		return waitingStyle.Render(fmt.Sprintf("%-*s", colWidth, "\u25CF"))
 	case session.StateInput:
 		return inputStyle.Render(fmt.Sprintf("%-*s", colWidth, "\u25C8"))
+	case session.StateExited:
+		return exitedStyle.Render(fmt.Sprintf("%-*s", colWidth, "\u2715"))
 	default:
 		return idleStyle.Render(fmt.Sprintf("%-*s", colWidth, "\u25CB"))
 	}
@@ -407,6 +722,8 @@ func stateDisplayWithIcon(state session.State) string {
 		return waitingStyle.Render("\u25CF waiting")
 	case session.StateInput:
 		return inputStyle.Render("\u25C8 input")
+	case session.StateExited:
+		return exitedStyle.Render("\u2715 exited")
 	default:
 		return idleStyle.Render("\u25CB idle")
 	}
@@ -432,12 +749,17 @@ func (m model) renderNormal() string {
 	filtered := m.filteredSessions()
 
 	// Count states from all sessions (not filtered)
-	activeCount, waitingCount, inputCount, idleCount := m.countStates()
+	activeCount, waitingCount, inputCount, idleCount, exitedCount := m.countStates()
 	totalCount := len(m.sessions)
 
 	// ---- Header ----
-	b.WriteString(m.renderHeader(width, activeCount, waitingCount, inputCount, idleCount, totalCount))
+	b.WriteString(m.renderHeader(width, activeCount, waitingCount, inputCount, idleCount, exitedCount, totalCount))
 	b.WriteString("\n")
+	hostStatus := m.renderHostStatus(width)
+	if hostStatus != "" {
+		b.WriteString(dimStyle.Render(hostStatus))
+		b.WriteString("\n")
+	}
 
 	// ---- Empty state ----
 	if totalCount == 0 {
@@ -452,38 +774,40 @@ func (m model) renderNormal() string {
 	}
 
 	// ---- Column widths ----
-	cw := computeColumnWidths(width)
+	visible, widths := m.layoutColumns(width)
 
 	b.WriteString("\n")
 
 	// ---- Column headers ----
-	b.WriteString(" ")
-	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", cw.state, "ST")))
-	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", cw.source, "SRC")))
-	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", cw.project, "PROJECT")))
-	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", cw.topic, "TOPIC")))
-	if cw.branch > 0 {
-		b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", cw.branch, "BRANCH")))
-	}
-	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %*s", cw.dur, "DUR")))
+	b.WriteString(renderColumnHeaders(visible, widths))
 	b.WriteString("\n")
 
-	// ---- Rows ----
-	maxRows := height - uiVerticalOverhead
+	// ---- Rows (scrolled to keep the cursor visible) ----
+	help := m.helpLines(width)
+	maxRows := height - uiVerticalOverhead - (len(help) - 2)
+	if hostStatus != "" {
+		maxRows--
+	}
 	if maxRows < 1 {
 		maxRows = 1
 	}
+	rows := m.tableRows()
+	offset := 0
+	if m.cursor >= maxRows {
+		offset = m.cursor - maxRows + 1
+	}
 
-	for i, s := range filtered {
-		if i >= maxRows {
-			remaining := len(filtered) - maxRows
-			b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more sessions", remaining)))
-			b.WriteString("\n")
-			break
-		}
-
+	for i := offset; i < len(rows) && i < offset+maxRows; i++ {
 		isSelected := i == m.cursor
-		b.WriteString(m.renderRow(s, isSelected, cw))
+		if rows[i].IsHeader() {
+			b.WriteString(m.renderGroupHeader(rows[i].Group, isSelected, width))
+		} else {
+			b.WriteString(m.renderRow(rows[i].Session, isSelected, visible, widths))
+		}
+		b.WriteString("\n")
+	}
+	if hidden := len(rows) - offset - maxRows; hidden > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more rows", hidden)))
 		b.WriteString("\n")
 	}
 
@@ -495,23 +819,72 @@ func (m model) renderNormal() string {
 			filterParts = append(filterParts, "state:"+stateFilterName(m.stateFilter))
 		}
 		if m.filterText != "" {
-			filterParts = append(filterParts, "text:\""+m.filterText+"\"")
+			filterParts = append(filterParts, m.filterText)
 		}
 		b.WriteString(helpStyle.Render("  filter: "+strings.Join(filterParts, " ")+" | "+fmt.Sprintf("%d/%d shown", len(filtered), totalCount)))
 		b.WriteString("\n")
 	}
 
-	// ---- Help line ----
+	// ---- Status line ----
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
+	// ---- Help lines ----
 	b.WriteString("\n")
-	sortName := sortFieldName(m.sortField)
-	b.WriteString(helpStyle.Render(fmt.Sprintf("  j/k: navigate  enter: detail  /: filter  f: state(%s)  s: sort(%s)  q: quit", stateFilterName(m.stateFilter), sortName)))
+	b.WriteString(helpStyle.Render(strings.Join(help, "\n")))
 
 	return b.String()
 }
 
+// helpLines returns the key hints of the session table, wrapped to fit width.
+func (m model) helpLines(width int) []string {
+	hints := []string{
+		"j/k: navigate",
+		"enter: detail",
+		"/: filter",
+		fmt.Sprintf("f: state(%s)", stateFilterName(m.stateFilter)),
+		fmt.Sprintf("s/S: sort(%s)", sortKeysName(m.sortKeys)),
+		fmt.Sprintf("g: group(%s)", m.groupBy),
+	}
+	if m.groupBy != grouping.None {
+		hints = append(hints, "\u2190/\u2192: fold")
+	}
+	switch {
+	case len(m.views) == 1:
+		hints = append(hints, "1: view")
+	case len(m.views) > 1:
+		hints = append(hints, fmt.Sprintf("1-%d: views", min(len(m.views), 9)))
+	}
+	hints = append(hints, "q: quit", "r: reply", "space: mark", "x: interrupt", "K: terminate",
+		"c: copy resume", "o: open resume", "R: recent", "H: history", "F: search", "t: tree")
+	return wrapHints(hints, width)
+}
+
+// wrapHints lays out key hints two spaces apart, starting a new line before
+// a hint that would run past width. A hint wider than width gets a line of
+// its own.
+func wrapHints(hints []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, hint := range hints {
+		if line != "" && utf8.RuneCountInString(line)+2+utf8.RuneCountInString(hint) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		line += "  " + hint
+	}
+	return append(lines, line)
+}
+
 // renderHeader builds the header bar with title and state counts.
-func (m model) renderHeader(width int, activeCount int, waitingCount int, inputCount int, idleCount int, totalCount int) string {
+func (m model) renderHeader(width int, activeCount int, waitingCount int, inputCount int, idleCount int, exitedCount int, totalCount int) string {
 	titleText := " cctop -- Claude Session Monitor"
+	if viewName := m.activeViewName(); viewName != "" {
+		titleText += " [" + viewName + "]"
+	}
 
 	var parts []headerPart
 	if activeCount > 0 {
@@ -530,6 +903,14 @@ func (m model) renderHeader(width int, activeCount int, waitingCount int, inputC
 		text := fmt.Sprintf("%d idle", idleCount)
 		parts = append(parts, headerPart{text, dimStyle.Render(text)})
 	}
+	if exitedCount > 0 {
+		text := fmt.Sprintf("%d exited", exitedCount)
+		parts = append(parts, headerPart{text, exitedStyle.Render(text)})
+	}
+	if conflicts := m.conflictCount(); conflicts > 0 {
+		text := fmt.Sprintf("\u26A0 %d editing same files", conflicts)
+		parts = append(parts, headerPart{text, conflictStyle.Render(text)})
+	}
 	quitText := "[q]uit"
 	parts = append(parts, headerPart{quitText, helpStyle.Render(quitText)})
 
@@ -555,58 +936,35 @@ func (m model) renderHeader(width int, activeCount int, waitingCount int, inputC
 }
 
 // renderRow renders a single session row.
-func (m model) renderRow(s session.Session, isSelected bool, cw columnWidths) string {
+func (m model) renderRow(s session.Session, isSelected bool, visible []column, widths []int) string {
 	var b strings.Builder
 
-	// Cursor indicator
-	if isSelected {
-		b.WriteString(selectedStyle.Render(" >"))
+	// Mark and cursor indicators
+	if m.marked[s.PID] {
+		b.WriteString(selectedStyle.Render("*"))
 	} else {
-		b.WriteString("  ")
+		b.WriteString(" ")
 	}
-
-	// State icon
-	b.WriteString(" ")
-	b.WriteString(stateIconStyled(s.State, cw.state))
-
-	// Source
-	var sourceStyled string
-	if s.Source.Type == "CLI" {
-		sourceStyled = cliSourceStyle.Render(fmt.Sprintf("%-*s", cw.source, s.Source.Type))
+	if isSelected {
+		b.WriteString(selectedStyle.Render(">"))
 	} else {
-		sourceStyled = ideSourceStyle.Render(fmt.Sprintf("%-*s", cw.source, s.Source.Type))
+		b.WriteString(" ")
 	}
-	b.WriteString(" ")
-	b.WriteString(sourceStyled)
 
-	// Apply dim style to the row if idle
+	// Apply dim style to the row if idle, and dim italic if exited
 	textStyleFn := func(text string) string { return text }
 	if s.State == session.StateIdle && !isSelected {
 		textStyleFn = func(text string) string { return dimStyle.Render(text) }
 	}
+	if s.State == session.StateExited && !isSelected {
+		textStyleFn = func(text string) string { return exitedStyle.Render(text) }
+	}
 
-	// Project
-	projectDisplay := truncateString(s.Project, cw.project)
-	b.WriteString(" ")
-	b.WriteString(textStyleFn(fmt.Sprintf("%-*s", cw.project, projectDisplay)))
-
-	// Topic
-	topicDisplay := truncateString(s.Topic, cw.topic)
-	b.WriteString(" ")
-	b.WriteString(textStyleFn(fmt.Sprintf("%-*s", cw.topic, topicDisplay)))
-
-	// Branch (optional)
-	if cw.branch > 0 {
-		branchDisplay := truncateString(s.Branch, cw.branch)
+	for i, col := range visible {
 		b.WriteString(" ")
-		b.WriteString(textStyleFn(fmt.Sprintf("%-*s", cw.branch, branchDisplay)))
+		b.WriteString(m.renderCell(col, widths[i], s, textStyleFn))
 	}
 
-	// Duration
-	durationStr := session.FormatDuration(s.Duration)
-	b.WriteString(" ")
-	b.WriteString(textStyleFn(fmt.Sprintf("%*s", cw.dur, durationStr)))
-
 	return b.String()
 }
 
@@ -623,6 +981,14 @@ func (m model) renderFilter() string {
 	b.WriteString(filterPromptStyle.Render("  Filter: "))
 	b.WriteString(m.filterInput.View())
 	b.WriteString("\n\n")
+	if m.filterErr != nil {
+		b.WriteString(statusErrorStyle.Render("  " + m.filterErr.Error()))
+	} else {
+		b.WriteString(helpStyle.Render("  e.g. state:waiting src:cli project:api* branch:feat/ age>10m tokens>100k -topic:refactor"))
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  fields: state src project topic branch cwd id tty pid msgs tokens age idle  ops: : = > >= < <=  AND OR NOT - ( )"))
+	b.WriteString("\n\n")
 	b.WriteString(helpStyle.Render("  enter: apply  esc: cancel"))
 
 	return b.String()
@@ -639,28 +1005,40 @@ func (m model) renderDetail() string {
 	b.WriteString(headerStyle.Width(width).Render(" cctop -- Session Detail"))
 	b.WriteString("\n\n")
 
-	filtered := m.filteredSessions()
-	if m.cursor >= len(filtered) {
+	s, ok := m.selectedSession()
+	if !ok {
 		b.WriteString("  No session selected\n")
 		b.WriteString("\n")
 		b.WriteString(helpStyle.Render("  esc: back"))
 		return b.String()
 	}
 
-	s := filtered[m.cursor]
-
 	details := []struct {
 		label string
 		value string
 	}{
 		{"State", stateDisplayWithIcon(s.State)},
-		{"Source", s.Source.String()},
+		{"Source", sourceLabel(s)},
+		{"IDE", s.IDEConnection},
+		{"Host", s.Host},
+		{"User", s.User},
 		{"PID", fmt.Sprintf("%d", s.PID)},
 		{"Project", s.Project},
 		{"CWD", s.CWD},
-		{"Branch", s.Branch},
+		{"Container", detailContainer(s)},
+		{"Branch", detailBranch(s)},
+		{"Repo", detailRepo(s)},
+		{"Git", gitStatusLong(s.Git)},
+		{"Model", s.Model},
+		{"Mode", detailMode(s)},
+		{"Context", detailContext(s)},
+		{"Compacted", detailCompactions(s)},
+		{"Resources", detailResources(s)},
 		{"Duration", session.FormatDuration(s.Duration)},
+		{"Ended", exitedAgo(s)},
 		{"Messages", fmt.Sprintf("~%d", s.Messages)},
+		{"Session", s.SessionID},
+		{"Resume", s.ResumeCommand()},
 		{"Topic", s.Topic},
 	}
 
@@ -671,15 +1049,31 @@ func (m model) renderDetail() string {
 		b.WriteString(fmt.Sprintf("  %s  %s\n", detailLabelStyle.Render(fmt.Sprintf("%-10s", detail.label)), detail.value))
 	}
 
+	if graphs := m.renderActivity(s, width); graphs != "" {
+		b.WriteString("\n")
+		b.WriteString(graphs)
+	}
+
+	if files := renderFilesTouched(s, width); files != "" {
+		b.WriteString("\n")
+		b.WriteString(files)
+	}
+
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("  esc: back  q: quit"))
+	b.WriteString(helpStyle.Render("  r: reply  c: copy resume  o: open resume  esc: back  q: quit"))
 
 	return b.String()
 }
 
-// countStates returns the count of active, waiting, input, and idle sessions.
-func (m model) countStates() (int, int, int, int) {
-	var activeCount, waitingCount, inputCount, idleCount int
+// countStates returns the count of active, waiting, input, idle, and exited sessions.
+func (m model) countStates() (int, int, int, int, int) {
+	var activeCount, waitingCount, inputCount, idleCount, exitedCount int
 	for _, s := range m.sessions {
 		switch s.State {
 		case session.StateActive:
@@ -690,9 +1084,20 @@ func (m model) countStates() (int, int, int, int) {
 			inputCount++
 		case session.StateIdle:
 			idleCount++
+		case session.StateExited:
+			exitedCount++
 		}
 	}
-	return activeCount, waitingCount, inputCount, idleCount
+	return activeCount, waitingCount, inputCount, idleCount, exitedCount
+}
+
+// exitedAgo returns how long ago an exited session last wrote its transcript,
+// or an empty string for live sessions.
+func exitedAgo(s session.Session) string {
+	if s.State != session.StateExited || s.LastActivity.IsZero() {
+		return ""
+	}
+	return session.FormatDuration(time.Since(s.LastActivity)) + " ago"
 }
 
 // truncateString truncates a string to maxLen, appending an ellipsis if needed.
@@ -700,13 +1105,94 @@ func truncateString(s string, maxLen int) string {
 	if maxLen <= 0 {
 		return ""
 	}
-	if len(s) <= maxLen {
+	if utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 1 {
 		return "\u2026"
 	}
-	return s[:maxLen-1] + "\u2026"
+	return string([]rune(s)[:maxLen-1]) + "\u2026"
+}
+
+// detailContext describes how full the context window is, warning when
+// auto-compaction is near.
+func detailContext(s session.Session) string {
+	percent := s.ContextPercent()
+	if percent < 0 {
+		return ""
+	}
+	text := fmt.Sprintf("%s / %s (%d%%)", session.FormatTokens(s.ContextTokens), session.FormatTokens(s.ContextLimit), percent)
+	if s.NearCompaction() {
+		return contextCriticalStyle.Render(text + " — near auto-compaction")
+	}
+	return text
+}
+
+// detailCompactions describes how many times the context was compacted.
+func detailCompactions(s session.Session) string {
+	switch s.Compactions {
+	case 0:
+		return ""
+	case 1:
+		return "1 time"
+	default:
+		return fmt.Sprintf("%d times", s.Compactions)
+	}
+}
+
+// detailResources describes the CPU and memory of the session's process
+// tree.
+func detailResources(s session.Session) string {
+	switch s.Processes {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%.1f%% CPU, %s in 1 process", s.CPUPercent, session.FormatSize(s.Memory))
+	default:
+		return fmt.Sprintf("%.1f%% CPU, %s in %d processes", s.CPUPercent, session.FormatSize(s.Memory), s.Processes)
+	}
+}
+
+// detailMode describes the permission mode, warning when tools run
+// without asking.
+func detailMode(s session.Session) string {
+	if s.PermissionsBypassed() {
+		return bypassStyle.Render(" "+s.PermissionMode+" ") + " tools run without asking"
+	}
+	return s.PermissionMode
+}
+
+// detailContainer names the session's container and its working directory
+// inside it.
+func detailContainer(s session.Session) string {
+	if s.Container == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (in %s)", s.Container, s.ContainerCWD)
+}
+
+// detailBranch describes the checked-out branch, noting when it differs
+// from the branch recorded in the transcript.
+func detailBranch(s session.Session) string {
+	branch := s.CurrentBranch()
+	if s.Git != nil && s.Git.Detached {
+		branch += " (detached HEAD)"
+	}
+	if s.BranchMismatch() {
+		branch += " " + branchMismatchStyle.Render("(transcript: "+s.Branch+")")
+	}
+	return branch
+}
+
+// detailRepo describes the repository, linked worktree (if any) and root.
+func detailRepo(s session.Session) string {
+	if s.Git == nil {
+		return ""
+	}
+	if s.Git.Worktree != "" {
+		return fmt.Sprintf("%s, worktree %s (%s)", s.Git.Repository, s.Git.Worktree, s.Git.Root)
+	}
+	return fmt.Sprintf("%s (%s)", s.Git.Repository, s.Git.Root)
 }
 
 // stateFilterName returns the display name for the current state filter.
@@ -720,19 +1206,9 @@ func stateFilterName(filter StateFilter) string {
 		return "input"
 	case FilterIdle:
 		return "idle"
+	case FilterExited:
+		return "exited"
 	default:
 		return "all"
 	}
 }
-
-// sortFieldName returns the display name for the current sort field.
-func sortFieldName(field SortField) string {
-	switch field {
-	case SortByDuration:
-		return "duration"
-	case SortByProject:
-		return "project"
-	default:
-		return "state"
-	}
-}
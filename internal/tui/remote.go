@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/hub"
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/remote"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// remoteWaitTimeout bounds how long --once and the first JSON snapshot wait
+// for remote hosts to report.
+const remoteWaitTimeout = 15 * time.Second
+
+// remoteSource supplies sessions from other machines: an ssh pool or a
+// hub client.
+type remoteSource interface {
+	Sessions() []session.Session
+	Status(now time.Time) []remote.HostStatus
+	WaitReady(timeout time.Duration)
+	Close()
+}
+
+// remoteSources is every configured remote source.
+type remoteSources []remoteSource
+
+// Sessions returns the latest sessions from every source.
+func (r remoteSources) Sessions() []session.Session {
+	var sessions []session.Session
+	for _, source := range r {
+		sessions = append(sessions, source.Sessions()...)
+	}
+	return sessions
+}
+
+// Status returns the host status of every source, in order.
+func (r remoteSources) Status(now time.Time) []remote.HostStatus {
+	var statuses []remote.HostStatus
+	for _, source := range r {
+		statuses = append(statuses, source.Status(now)...)
+	}
+	return statuses
+}
+
+// WaitReady waits for each source in turn, sharing one timeout.
+func (r remoteSources) WaitReady(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for _, source := range r {
+		source.WaitReady(max(0, time.Until(deadline)))
+	}
+}
+
+// Close stops every source.
+func (r remoteSources) Close() {
+	for _, source := range r {
+		source.Close()
+	}
+}
+
+// startRemotes connects to the --host targets and the --hub, or returns nil
+// when there are none.
+func startRemotes(opts Options) remoteSources {
+	var sources remoteSources
+	if len(opts.Hosts) > 0 {
+		command := opts.RemoteCommand
+		if command == "" {
+			command = "cctop"
+		}
+		sources = append(sources, remote.Start(opts.Hosts, remote.SSHDialer(command)))
+	}
+	if opts.Hub != "" {
+		// An agent on this machine reports under its hostname; its sessions
+		// are already listed locally
+		hostname, _ := os.Hostname()
+		sources = append(sources, hub.Connect(opts.Hub, opts.Token, hostname))
+	}
+	return sources
+}
+
+// discoverSessions finds local sessions, attaches their CPU and memory use
+// from a fresh process sample, and appends the latest sessions reported by
+// remote hosts. The sample is returned for the process tree view; err says
+// why processes could not be listed.
+func discoverSessions(opts session.DiscoverOptions, remotes remoteSources, sampler *process.Sampler) ([]session.Session, process.Table, error) {
+	sessions := session.Discover(opts)
+	table, err := sampler.Sample(time.Now())
+	if err == nil {
+		session.AttachResources(sessions, table)
+	}
+	return append(sessions, remotes.Sessions()...), table, err
+}
+
+// hostLabel returns the HOST column text for a session.
+func hostLabel(s session.Session) string {
+	if s.Host == "" {
+		return "local"
+	}
+	return remote.HostLabel(s.Host)
+}
+
+// withHostColumn inserts the host column after source (or state) unless it
+// is already shown.
+func withHostColumn(ids []string) []string {
+	if slices.Contains(ids, "host") {
+		return ids
+	}
+	at := 0
+	for i, id := range ids {
+		if id == "state" || id == "source" {
+			at = i + 1
+		}
+	}
+	return slices.Insert(slices.Clone(ids), at, "host")
+}
+
+// renderHostStatus renders one line summarizing each remote host's
+// connection, or "" without --host or --hub.
+func (m model) renderHostStatus(width int) string {
+	if m.remotes == nil {
+		return ""
+	}
+
+	now := time.Now()
+	var parts []string
+	for _, status := range m.remotes.Status(now) {
+		parts = append(parts, hostStatusText(status, now))
+	}
+	return truncateString("  hosts: "+strings.Join(parts, "  "), width)
+}
+
+// hostStatusText describes one host: its latency while connected, or why
+// it is not and when it retries.
+func hostStatusText(status remote.HostStatus, now time.Time) string {
+	switch status.State {
+	case remote.HostConnected:
+		return fmt.Sprintf("%s ✓ %dms", status.Label, status.Latency.Milliseconds())
+	case remote.HostStale:
+		return fmt.Sprintf("%s ⚠ no data for %s", status.Label, session.FormatDuration(now.Sub(status.LastSeen)))
+	case remote.HostConnecting:
+		return status.Label + " … connecting"
+	default:
+		retry := ""
+		if wait := status.RetryAt.Sub(now); wait > 0 {
+			retry = fmt.Sprintf(", retry in %s", session.FormatDuration(wait.Round(time.Second)))
+		}
+		return fmt.Sprintf("%s ✗ %s%s", status.Label, truncateString(status.Err, 40), retry)
+	}
+}
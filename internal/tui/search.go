@@ -0,0 +1,260 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Jevs21/cctop/internal/search"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// maxSearchResults caps the number of full-text search results kept.
+const maxSearchResults = 500
+
+// searchResultsMsg carries the outcome of a background full-text search.
+type searchResultsMsg struct {
+	cache   *search.Cache
+	query   string
+	results []search.Result
+	err     error
+}
+
+// searchCmd updates the on-disk cache with transcript lines newly written in
+// claudeDirs and runs the query against it. The cache is opened on first use
+// and handed back to the model for reuse.
+func searchCmd(cache *search.Cache, claudeDirs []string, query string) tea.Cmd {
+	return func() tea.Msg {
+		if cache == nil {
+			cache = search.Open(search.DefaultPath())
+		}
+
+		changed, err := cache.Update(claudeDirs...)
+		if err != nil {
+			return searchResultsMsg{cache: cache, query: query, err: err}
+		}
+		if changed {
+			if saveErr := cache.Save(); saveErr != nil {
+				return searchResultsMsg{cache: cache, query: query, err: saveErr}
+			}
+		}
+
+		return searchResultsMsg{cache: cache, query: query, results: cache.Search(query, maxSearchResults)}
+	}
+}
+
+// openSearch shows the full-text search input.
+func (m model) openSearch() (tea.Model, tea.Cmd) {
+	m.mode = ModeSearch
+	m.searchInput.SetValue(m.searchQuery)
+	cmd := m.searchInput.Focus()
+	return m, cmd
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		query := strings.TrimSpace(m.searchInput.Value())
+		m.searchInput.Blur()
+		if query == "" {
+			m.mode = ModeNormal
+			return m, nil
+		}
+		m.searchQuery = query
+		m.searchResults = nil
+		m.searchCursor = 0
+		m.searchErr = nil
+		m.mode = ModeSearchResults
+		if m.searching {
+			return m, nil
+		}
+		m.searching = true
+		return m, searchCmd(m.searchCache, session.ConfigDirs(m.claudeDirs), query)
+	case "esc":
+		m.searchInput.Blur()
+		m.mode = ModeNormal
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m model) updateSearchResults(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		m.mode = ModeNormal
+	case "j", "down":
+		if m.searchCursor < len(m.searchResults)-1 {
+			m.searchCursor++
+		}
+	case "k", "up":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+	case "/", "F":
+		return m.openSearch()
+	case "c":
+		if result, ok := m.selectedSearchResult(); ok {
+			return m, copyResumeCmd(searchResultSession(result))
+		}
+	case "o":
+		if result, ok := m.selectedSearchResult(); ok {
+			return m, openResumeCmd(searchResultSession(result))
+		}
+	}
+	return m, nil
+}
+
+// selectedSearchResult returns the search result under the cursor, if any.
+func (m model) selectedSearchResult() (search.Result, bool) {
+	if m.searchCursor < 0 || m.searchCursor >= len(m.searchResults) {
+		return search.Result{}, false
+	}
+	return m.searchResults[m.searchCursor], true
+}
+
+// searchResultSession adapts a search result for the session resume actions.
+func searchResultSession(result search.Result) session.Session {
+	return session.Session{
+		CWD:            result.CWD,
+		State:          session.StateExited,
+		Project:        result.Project,
+		SessionID:      result.SessionID,
+		TranscriptPath: result.Path,
+	}
+}
+
+// renderSearch renders the full-text search input view.
+func (m model) renderSearch() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+
+	b.WriteString(headerStyle.Width(width).Render(" cctop -- Search Transcripts"))
+	b.WriteString("\n\n")
+	b.WriteString(filterPromptStyle.Render("  Search: "))
+	b.WriteString(m.searchInput.View())
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("  Matches prompts, replies, and tool inputs (paths, commands) in all transcripts"))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("  enter: search  esc: cancel"))
+
+	return b.String()
+}
+
+// renderSearchResults renders the list of full-text search matches.
+func (m model) renderSearchResults() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+	height := m.windowHeight
+	if height == 0 {
+		height = 24
+	}
+
+	titleText := " cctop -- Search: " + m.searchQuery
+	countText := ""
+	if !m.searching && m.searchErr == nil {
+		countText = fmt.Sprintf("%d matches", len(m.searchResults))
+		if len(m.searchResults) >= maxSearchResults {
+			countText = fmt.Sprintf("%d+ matches", maxSearchResults)
+		}
+	}
+	middlePad := max(width-len(titleText)-len(countText)-1, 1)
+	b.WriteString(headerStyle.Width(width).Render(titleText + strings.Repeat(" ", middlePad) + countText))
+	b.WriteString("\n\n")
+
+	switch {
+	case m.searching:
+		b.WriteString(dimStyle.Render("  Updating cache and searching..."))
+		b.WriteString("\n")
+		return b.String()
+	case m.searchErr != nil:
+		b.WriteString(statusErrorStyle.Render("  Search failed: " + m.searchErr.Error()))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  /: new search  esc: back"))
+		return b.String()
+	case len(m.searchResults) == 0:
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  No transcript lines match %q", m.searchQuery)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  /: new search  esc: back"))
+		return b.String()
+	}
+
+	// ---- Column widths ----
+	const kindWidth = 10
+	projectWidth := 20
+	snippetWidth := max(width-historyTimeColWidth-projectWidth-kindWidth-fixedColumnSpacing-2, minTopicColWidth)
+
+	// ---- Column headers ----
+	b.WriteString("  ")
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, "TIME")))
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", projectWidth, "SESSION")))
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", kindWidth, "KIND")))
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %-*s", snippetWidth, "MATCH")))
+	b.WriteString("\n")
+
+	// ---- Rows (scrolled to keep the cursor visible) ----
+	maxRows := max(height-uiVerticalOverhead, 1)
+	offset := 0
+	if m.searchCursor >= maxRows {
+		offset = m.searchCursor - maxRows + 1
+	}
+
+	for i := offset; i < len(m.searchResults) && i < offset+maxRows; i++ {
+		result := m.searchResults[i]
+
+		if i == m.searchCursor {
+			b.WriteString(selectedStyle.Render(" >"))
+		} else {
+			b.WriteString("  ")
+		}
+
+		sessionLabel := result.Project
+		if sessionLabel == "" && len(result.SessionID) >= 8 {
+			sessionLabel = result.SessionID[:8]
+		}
+
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %-*s", historyTimeColWidth, formatHistoryTime(result.Timestamp))))
+		b.WriteString(fmt.Sprintf(" %-*s", projectWidth, truncateString(sessionLabel, projectWidth)))
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %-*s", kindWidth, truncateString(result.Kind, kindWidth))))
+		b.WriteString(" ")
+		b.WriteString(highlightSnippet(result, snippetWidth))
+		b.WriteString("\n")
+	}
+
+	if hidden := len(m.searchResults) - offset - maxRows; hidden > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more matches", hidden)))
+		b.WriteString("\n")
+	}
+
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  j/k: navigate  /: new search  c: copy resume  o: open resume  esc: back"))
+
+	return b.String()
+}
+
+// highlightSnippet truncates a result snippet to width and highlights the
+// matched text when it is visible.
+func highlightSnippet(result search.Result, width int) string {
+	snippet := truncateString(result.Snippet, width)
+	if result.MatchEnd <= result.MatchStart || result.MatchEnd > len(snippet) {
+		return snippet
+	}
+	return snippet[:result.MatchStart] +
+		searchMatchStyle.Render(snippet[result.MatchStart:result.MatchEnd]) +
+		snippet[result.MatchEnd:]
+}
@@ -25,6 +25,10 @@ var (
 	idleStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")) // Dim gray
 
+	exitedStyle = lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color("238")) // Darker gray, italic
+
 	// Source styles
 	cliSourceStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("51")) // Cyan
@@ -62,4 +66,49 @@ var (
 	filterPromptStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(lipgloss.Color("214")) // Orange
+
+	// Branch that no longer matches the transcript's recorded branch
+	branchMismatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214")) // Orange
+
+	// Warning for sessions editing the same files as another live session
+	conflictStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("196")) // Red
+
+	// Sessions running with permissions bypassed
+	bypassStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("160")) // White on red
+
+	// Context window filling up, and close to auto-compaction
+	contextWarnStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("214")) // Orange
+
+	contextCriticalStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("196")) // Red
+
+	// Activity sparklines and graphs
+	activityStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("114")) // Soft green
+
+	// Group header rows in the grouped session table
+	groupHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("75")) // Blue
+
+	// Highlight for matched text in search results
+	searchMatchStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("0")).
+				Background(lipgloss.Color("214")) // Black on orange
+
+	// Status line styles for action results
+	statusOKStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("46")) // Green
+
+	statusErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196")) // Red
 )
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const (
+	// heavyCPUPercent is the CPU% from which a process is highlighted.
+	heavyCPUPercent = 50
+
+	// heavyMemory is the resident memory from which a process is highlighted.
+	heavyMemory = 1 << 30
+)
+
+// treeLine is one line of the process tree view: a session heading, or a
+// process with the branch drawing that places it in its session's tree.
+type treeLine struct {
+	session *session.Session // Set for session headings
+	proc    process.Process
+	prefix  string // Branch drawing before the command, e.g. "│  └─ "
+}
+
+// openTree switches to the process tree view, drawn from the process
+// sample taken with every refresh.
+func (m model) openTree() (tea.Model, tea.Cmd) {
+	m.mode = ModeTree
+	m.treeOffset = 0
+	return m, nil
+}
+
+func (m model) updateTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "t":
+		m.mode = ModeNormal
+	case "j", "down":
+		if m.treeOffset < m.treeMaxOffset() {
+			m.treeOffset++
+		}
+	case "k", "up":
+		if m.treeOffset > 0 {
+			m.treeOffset--
+		}
+	case "g", "home":
+		m.treeOffset = 0
+	}
+	return m, nil
+}
+
+// treeMaxOffset is the scroll offset that shows the last tree lines.
+func (m model) treeMaxOffset() int {
+	height := m.windowHeight
+	if height == 0 {
+		height = 24
+	}
+	return max(len(m.treeLines())-max(height-uiVerticalOverhead, 1), 0)
+}
+
+// treeLines lists each visible local session followed by its claude process
+// and every descendant, in table order. Sessions on remote hosts, and ones
+// whose process is gone, have no local tree and are skipped.
+func (m model) treeLines() []treeLine {
+	var lines []treeLine
+	for _, s := range m.filteredSessions() {
+		if s.PID <= 0 || s.Host != "" {
+			continue
+		}
+		root, ok := m.procs.Get(s.PID)
+		if !ok {
+			continue
+		}
+		lines = append(lines, treeLine{session: &s}, treeLine{proc: root})
+		lines = m.appendChildren(lines, root.PID, "", map[int]bool{root.PID: true})
+	}
+	return lines
+}
+
+// appendChildren adds pid's children, drawing branches below indent.
+func (m model) appendChildren(lines []treeLine, pid int, indent string, seen map[int]bool) []treeLine {
+	children := m.procs.Children(pid)
+	for i, child := range children {
+		if seen[child.PID] {
+			continue
+		}
+		seen[child.PID] = true
+		branch, next := "├─ ", "│  "
+		if i == len(children)-1 {
+			branch, next = "└─ ", "   "
+		}
+		lines = append(lines, treeLine{proc: child, prefix: indent + branch})
+		lines = m.appendChildren(lines, child.PID, indent+next, seen)
+	}
+	return lines
+}
+
+// renderTree renders the htop-style process tree of every session.
+func (m model) renderTree() string {
+	var b strings.Builder
+	width := m.windowWidth
+	if width == 0 {
+		width = 80
+	}
+	height := m.windowHeight
+	if height == 0 {
+		height = 24
+	}
+
+	lines := m.treeLines()
+	processCount := 0
+	for _, line := range lines {
+		if line.session == nil {
+			processCount++
+		}
+	}
+
+	// ---- Header ----
+	titleText := " cctop -- Process Tree"
+	countText := fmt.Sprintf("%d processes", processCount)
+	middlePad := max(width-len(titleText)-len(countText)-1, 1)
+	b.WriteString(headerStyle.Width(width).Render(titleText + strings.Repeat(" ", middlePad) + countText))
+	b.WriteString("\n")
+
+	switch {
+	case m.procsErr != nil:
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  Cannot list processes: %v", m.procsErr)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  esc: back"))
+		return b.String()
+	case len(lines) == 0:
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("  No local sessions with a running process"))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("  esc: back"))
+		return b.String()
+	}
+
+	// ---- Column headers ----
+	const pidWidth, cpuWidth, rssWidth, timeWidth = 7, 6, 6, 8
+	commandWidth := max(width-pidWidth-cpuWidth-rssWidth-timeWidth-fixedColumnSpacing-6, minTopicColWidth)
+
+	b.WriteString("\n  ")
+	b.WriteString(columnHeaderStyle.Render(fmt.Sprintf(" %*s %*s %*s %*s  %s", pidWidth, "PID", cpuWidth, "CPU%", rssWidth, "RSS", timeWidth, "TIME", "COMMAND")))
+	b.WriteString("\n")
+
+	// ---- Rows (scrolled by j/k) ----
+	maxRows := max(height-uiVerticalOverhead, 1)
+	offset := min(m.treeOffset, m.treeMaxOffset())
+
+	for i := offset; i < len(lines) && i < offset+maxRows; i++ {
+		line := lines[i]
+		if line.session != nil {
+			s := *line.session
+			heading := s.Project
+			if topic := topicLabel(s); topic != "" {
+				heading += " — " + topic
+			}
+			b.WriteString(" " + stateIconStyled(s.State, 2))
+			b.WriteString(truncateString(heading, width-4))
+			b.WriteString("\n")
+			continue
+		}
+
+		p := line.proc
+		cpu := fmt.Sprintf(" %*.1f", cpuWidth, p.CPUPercent)
+		if p.CPUPercent >= heavyCPUPercent {
+			cpu = contextWarnStyle.Render(cpu)
+		}
+		rss := fmt.Sprintf(" %*s", rssWidth, session.FormatSize(p.RSS))
+		if p.RSS >= heavyMemory {
+			rss = contextWarnStyle.Render(rss)
+		}
+
+		b.WriteString("  ")
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %*d", pidWidth, p.PID)))
+		b.WriteString(cpu)
+		b.WriteString(rss)
+		b.WriteString(dimStyle.Render(fmt.Sprintf(" %*s", timeWidth, session.FormatDuration(p.Runtime))))
+		b.WriteString("  ")
+		b.WriteString(dimStyle.Render(line.prefix))
+		b.WriteString(truncateString(p.Label(), commandWidth-len([]rune(line.prefix))))
+		b.WriteString("\n")
+	}
+
+	if hidden := len(lines) - offset - maxRows; hidden > 0 {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  ... %d more lines", hidden)))
+		b.WriteString("\n")
+	}
+
+	if statusText := m.renderStatus(); statusText != "" {
+		b.WriteString("\n")
+		b.WriteString(statusText)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  j/k: scroll  g: top  esc: back"))
+
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// withUserColumn inserts the user column after host, source or state unless
+// it is already shown.
+func withUserColumn(ids []string) []string {
+	if slices.Contains(ids, "user") {
+		return ids
+	}
+	at := 0
+	for i, id := range ids {
+		if id == "state" || id == "source" || id == "host" {
+			at = i + 1
+		}
+	}
+	return slices.Insert(slices.Clone(ids), at, "user")
+}
+
+// topicLabel returns the session's topic, or "no access" for another user's
+// session whose transcripts cannot be read.
+func topicLabel(s session.Session) string {
+	if s.Topic == "" && s.NoAccess {
+		return session.NoAccessTopic
+	}
+	return s.Topic
+}
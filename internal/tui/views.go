@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Jevs21/cctop/internal/config"
+	"github.com/Jevs21/cctop/internal/grouping"
+	"github.com/Jevs21/cctop/internal/query"
+)
+
+// viewIndex returns the index of the named view, or -1 if there is none.
+func viewIndex(views []config.View, name string) int {
+	for i, view := range views {
+		if view.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateViews checks every view's filter, sort keys and columns so that
+// mistakes in the config file are reported at startup.
+func validateViews(views []config.View) error {
+	for _, view := range views {
+		if _, err := query.Parse(view.Filter); err != nil {
+			return fmt.Errorf("view %q: invalid filter %q: %w", view.Name, view.Filter, err)
+		}
+		if _, err := parseSortKeys(view.Sort); err != nil {
+			return fmt.Errorf("view %q: %w", view.Name, err)
+		}
+		if _, err := parseColumns(view.Columns); err != nil {
+			return fmt.Errorf("view %q: %w", view.Name, err)
+		}
+		if _, err := grouping.Parse(view.Group); err != nil {
+			return fmt.Errorf("view %q: %w", view.Name, err)
+		}
+	}
+	return nil
+}
+
+// applyView switches to the view at index, or to the default view when
+// index is out of range. The filter, sort keys, columns and grouping are replaced;
+// fields the view leaves empty fall back to the defaults.
+func (m *model) applyView(index int) {
+	var view config.View
+	if index >= 0 && index < len(m.views) {
+		view = m.views[index]
+	} else {
+		index = -1
+	}
+	m.activeView = index
+
+	m.filterText = view.Filter
+	m.filterQuery, _ = query.Parse(view.Filter)
+
+	m.sortKeys, _ = parseSortKeys(view.Sort)
+	if len(m.sortKeys) == 0 {
+		m.sortKeys = defaultSortKeys
+	}
+
+	m.columns = view.Columns
+	if len(m.columns) == 0 {
+		m.columns = defaultColumns
+		if m.showHosts {
+			m.columns = withHostColumn(m.columns)
+		}
+		if m.allUsers {
+			m.columns = withUserColumn(m.columns)
+		}
+	}
+
+	m.groupBy, _ = grouping.Parse(view.Group)
+}
+
+// activeViewName returns the name of the current view, or "" for the default.
+func (m model) activeViewName() string {
+	if m.activeView < 0 || m.activeView >= len(m.views) {
+		return ""
+	}
+	return m.views[m.activeView].Name
+}
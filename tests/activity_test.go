@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/activity"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		series   []int
+		expected string
+	}{
+		{[]int{0, 0, 0}, "▁▁▁"},
+		{[]int{-1, -1, 0}, "  ▁"},
+		{[]int{0, 4, 8}, "▁▅█"},
+		// Any activity rises above the idle bar, however small
+		{[]int{1, 1000}, "▂█"},
+		{[]int{-1, 7, 0, 7}, " █▁█"},
+	}
+
+	for _, tt := range tests {
+		if got := activity.Sparkline(tt.series); got != tt.expected {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.series, got, tt.expected)
+		}
+	}
+}
+
+func TestGraph(t *testing.T) {
+	rows := activity.Graph([]int{-1, 0, 1, 8, 16}, 2)
+	expected := []string{
+		"    █",
+		" ·▁██",
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Graph = %q, want %q", rows, expected)
+	}
+
+	// Each row resolves eight levels: half the peak fills the bottom row
+	rows = activity.Graph([]int{4, 8}, 2)
+	expected = []string{" █", "██"}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Graph = %q, want %q", rows, expected)
+	}
+}
+
+func TestTrackerBuckets(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var tracker activity.Tracker
+
+	tracker.Track(start, []activity.Sample{{Key: "a", Lines: 10, Tokens: 1000}})
+	tracker.Track(start.Add(20*time.Second), []activity.Sample{{Key: "a", Lines: 12, Tokens: 1500}})
+	tracker.Track(start.Add(40*time.Second), []activity.Sample{{Key: "a", Lines: 15, Tokens: 1800}})
+	tracker.Track(start.Add(70*time.Second), []activity.Sample{{Key: "a", Lines: 16, Tokens: 2000}})
+	now := start.Add(70 * time.Second)
+
+	// Growth within a minute is summed into that minute's bucket; minutes
+	// before tracking began are unknown
+	if series := tracker.Series("a", now, 3, activity.Tokens); !reflect.DeepEqual(series, []int{-1, 800, 200}) {
+		t.Errorf("token series = %v, want [-1 800 200]", series)
+	}
+	if series := tracker.Series("a", now, 3, activity.Lines); !reflect.DeepEqual(series, []int{-1, 5, 1}) {
+		t.Errorf("line series = %v, want [-1 5 1]", series)
+	}
+	if series := tracker.Series("unknown", now, 2, activity.Tokens); !reflect.DeepEqual(series, []int{-1, -1}) {
+		t.Errorf("series of an untracked key = %v, want [-1 -1]", series)
+	}
+}
+
+func TestTrackerCounterReset(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var tracker activity.Tracker
+
+	tracker.Track(start, []activity.Sample{{Key: "a", Lines: 100, Tokens: 50_000}})
+	// A rewritten transcript shrinks: no negative growth, a new baseline
+	tracker.Track(start.Add(10*time.Second), []activity.Sample{{Key: "a", Lines: 5, Tokens: 2_000}})
+	tracker.Track(start.Add(20*time.Second), []activity.Sample{{Key: "a", Lines: 8, Tokens: 2_600}})
+
+	series := tracker.Series("a", start.Add(20*time.Second), 1, activity.Tokens)
+	if !reflect.DeepEqual(series, []int{600}) {
+		t.Errorf("series after a reset = %v, want [600] from the new baseline", series)
+	}
+}
+
+func TestTrackerDropsSessions(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var tracker activity.Tracker
+
+	tracker.Track(start, []activity.Sample{{Key: "a"}, {Key: "b"}})
+	tracker.Track(start.Add(time.Second), []activity.Sample{{Key: "b"}})
+	if tracker.Tracked("a") || !tracker.Tracked("b") {
+		t.Errorf("Tracked(a), Tracked(b) = %v, %v; want sessions that are gone dropped", tracker.Tracked("a"), tracker.Tracked("b"))
+	}
+
+	// Buckets that fall out of the window are pruned
+	tracker.Track(start.Add(2*time.Second), []activity.Sample{{Key: "b", Tokens: 10}})
+	if series := tracker.Series("b", start, 1, activity.Tokens); series[0] != 10 {
+		t.Fatalf("first minute = %d, want 10", series[0])
+	}
+	later := start.Add(activity.Minutes * time.Minute)
+	tracker.Track(later, []activity.Sample{{Key: "b", Tokens: 10}})
+	if series := tracker.Series("b", later, activity.Minutes+1, activity.Tokens); series[0] != 0 {
+		t.Errorf("minute %d minutes ago = %d, want 0 after pruning", activity.Minutes, series[0])
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/config"
+	"github.com/Jevs21/cctop/internal/tui"
+)
+
+func TestConfigLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := config.Load(filepath.Join(dir, "missing.json"))
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if len(cfg.Views) != 0 {
+			t.Errorf("got %d views, want 0", len(cfg.Views))
+		}
+	})
+
+	t.Run("views", func(t *testing.T) {
+		path := filepath.Join(dir, "config.json")
+		content := `{"views": [
+			{"name": "waiting", "filter": "state:waiting", "sort": ["-in_state", "project"]},
+			{"name": "cost", "sort": ["-tokens"], "columns": ["state", "project", "tokens"]}
+		]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		view, ok := cfg.FindView("cost")
+		if !ok {
+			t.Fatal("view cost not found")
+		}
+		if len(view.Columns) != 3 || view.Sort[0] != "-tokens" {
+			t.Errorf("view cost = %+v", view)
+		}
+		if _, ok := cfg.FindView("nope"); ok {
+			t.Error("FindView(nope) found a view")
+		}
+	})
+
+	t.Run("ides", func(t *testing.T) {
+		path := filepath.Join(dir, "ides.json")
+		content := `{"ides": [{"match": "Sublime Text", "name": "Sublime", "icon": "S", "color": "208"}]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		expected := config.IDE{Match: "Sublime Text", Name: "Sublime", Icon: "S", Color: "208"}
+		if len(cfg.IDEs) != 1 || cfg.IDEs[0] != expected {
+			t.Errorf("IDEs = %+v, want [%+v]", cfg.IDEs, expected)
+		}
+
+		if err := os.WriteFile(path, []byte(`{"ides": [{"match": "Sublime Text"}]}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := config.Load(path); err == nil {
+			t.Error("Load accepted an ide mapping without a name")
+		}
+	})
+
+	t.Run("unnamed view", func(t *testing.T) {
+		path := filepath.Join(dir, "unnamed.json")
+		if err := os.WriteFile(path, []byte(`{"views": [{"filter": "state:idle"}]}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := config.Load(path); err == nil {
+			t.Error("Load accepted a view without a name")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(dir, "broken.json")
+		if err := os.WriteFile(path, []byte(`{"views": [`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := config.Load(path); err == nil {
+			t.Error("Load accepted invalid JSON")
+		}
+	})
+}
+
+func TestParseSortSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"state", 1, false},
+		{"state,-in_state,project", 3, false},
+		{"+dur,-tokens", 2, false},
+		{"state,colour", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			keys, err := tui.ParseSortSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSortSpec(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if len(keys) != tt.want {
+				t.Errorf("ParseSortSpec(%q) = %v, want %d keys", tt.spec, keys, tt.want)
+			}
+		})
+	}
+}
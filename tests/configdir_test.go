@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestDefaultClaudeDir(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv(session.ConfigDirEnv, "")
+	if got := session.DefaultClaudeDir(); got != "/home/dev/.claude" {
+		t.Errorf("DefaultClaudeDir() = %q, want /home/dev/.claude", got)
+	}
+
+	t.Setenv(session.ConfigDirEnv, "/srv/claude-work/")
+	if got := session.DefaultClaudeDir(); got != "/srv/claude-work" {
+		t.Errorf("DefaultClaudeDir() with %s = %q, want /srv/claude-work", session.ConfigDirEnv, got)
+	}
+}
+
+func TestConfigDirs(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+	t.Setenv(session.ConfigDirEnv, "")
+
+	got := session.ConfigDirs([]string{"/home/dev/.claude-work", "/home/dev/.claude", "/home/dev/.claude-work/", ""})
+	expected := []string{"/home/dev/.claude", "/home/dev/.claude-work"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ConfigDirs() = %q, want %q", got, expected)
+	}
+}
+
+// startFakeClaude runs a process named claude in dir with the given extra
+// environment, so discovery finds it in ps.
+func startFakeClaude(t *testing.T, dir string, env ...string) *exec.Cmd {
+	t.Helper()
+	cmd := fakeClaudeCommand(t, dir, env...)
+	startCommand(t, cmd)
+	return cmd
+}
+
+// fakeClaudeCommand returns an unstarted process named claude in dir.
+func fakeClaudeCommand(t *testing.T, dir string, env ...string) *exec.Cmd {
+	t.Helper()
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+	data, err := os.ReadFile(sleep)
+	if err != nil {
+		t.Skipf("cannot copy sleep: %v", err)
+	}
+	binary := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(binary, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(binary, "60")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd
+}
+
+// startCommand starts a fake claude and kills it when the test ends.
+func startCommand(t *testing.T, cmd *exec.Cmd) {
+	t.Helper()
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start fake claude: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+}
+
+// writeTranscript writes a one-prompt transcript for cwd under claudeDir.
+func writeTranscript(t *testing.T, claudeDir, cwd, sessionID, prompt string) {
+	t.Helper()
+	projectDir := filepath.Join(claudeDir, "projects", session.EncodePath(cwd))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"type":"user","message":{"role":"user","content":"` + prompt + `"},"cwd":"` + cwd + `","sessionId":"` + sessionID + `"}` + "\n"
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverConfigDirs(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reading another process's environment needs /proc")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(session.ConfigDirEnv, "")
+
+	// One session started with CLAUDE_CONFIG_DIR, one whose directory is
+	// only known from --claude-dir
+	profileDir := filepath.Join(t.TempDir(), "profile")
+	extraDir := filepath.Join(t.TempDir(), "extra")
+	envProject, _ := filepath.EvalSymlinks(t.TempDir())
+	extraProject, _ := filepath.EvalSymlinks(t.TempDir())
+	writeTranscript(t, profileDir, envProject, "aaaaaaaa-0000-4000-8000-000000000001", "profile session")
+	writeTranscript(t, extraDir, extraProject, "bbbbbbbb-0000-4000-8000-000000000002", "extra dir session")
+
+	envClaude := startFakeClaude(t, envProject, session.ConfigDirEnv+"="+profileDir)
+	extraClaude := startFakeClaude(t, extraProject)
+
+	var sessions []session.Session
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sessions = session.Discover(session.DiscoverOptions{ClaudeDirs: []string{extraDir}})
+		if len(findPIDs(sessions, envClaude.Process.Pid, extraClaude.Process.Pid)) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	found := findPIDs(sessions, envClaude.Process.Pid, extraClaude.Process.Pid)
+	if len(found) != 2 {
+		t.Fatalf("discovered %d of the 2 fake sessions", len(found))
+	}
+
+	tests := []struct {
+		pid       int
+		claudeDir string
+		topic     string
+	}{
+		{envClaude.Process.Pid, profileDir, "profile session"},
+		{extraClaude.Process.Pid, extraDir, "extra dir session"},
+	}
+	for _, tt := range tests {
+		s := found[tt.pid]
+		if s.ClaudeDir != tt.claudeDir || s.Topic != tt.topic {
+			t.Errorf("pid %d: ClaudeDir %q topic %q, want %q %q", tt.pid, s.ClaudeDir, s.Topic, tt.claudeDir, tt.topic)
+		}
+	}
+}
+
+// findPIDs returns the sessions with the given PIDs, keyed by PID.
+func findPIDs(sessions []session.Session, pids ...int) map[int]session.Session {
+	found := make(map[int]session.Session)
+	for _, s := range sessions {
+		for _, pid := range pids {
+			if s.PID == pid {
+				found[pid] = s
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+const testContainerID = "4f1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c"
+
+func TestParseContainerID(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		id      string
+		runtime string
+	}{
+		{
+			name:    "cgroup v1 docker",
+			text:    "12:memory:/docker/" + testContainerID + "\n11:cpu,cpuacct:/docker/" + testContainerID + "\n",
+			id:      testContainerID,
+			runtime: "docker",
+		},
+		{
+			name:    "cgroup v2 systemd docker scope",
+			text:    "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			id:      testContainerID,
+			runtime: "docker",
+		},
+		{
+			name:    "podman libpod scope",
+			text:    "0::/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + testContainerID + ".scope/container\n",
+			id:      testContainerID,
+			runtime: "podman",
+		},
+		{
+			name:    "kubernetes containerd",
+			text:    "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1234.slice/cri-containerd-" + testContainerID + ".scope\n",
+			id:      testContainerID,
+			runtime: "docker",
+		},
+		{
+			name:    "docker mountinfo behind a cgroup namespace",
+			text:    "640 620 253:1 /var/lib/docker/containers/" + testContainerID + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n",
+			id:      testContainerID,
+			runtime: "docker",
+		},
+		{
+			name:    "podman mountinfo",
+			text:    "702 690 0:51 /containers/storage/overlay-containers/" + testContainerID + "/userdata/hostname /etc/hostname rw - tmpfs tmpfs rw\n",
+			id:      testContainerID,
+			runtime: "podman",
+		},
+		{
+			name: "host user session",
+			text: "0::/user.slice/user-1000.slice/session-3.scope\n",
+		},
+		{
+			name: "hex without a runtime hint",
+			text: "0::/system.slice/" + testContainerID + ".service\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, runtime := session.ParseContainerID(tt.text)
+			if id != tt.id || runtime != tt.runtime {
+				t.Errorf("ParseContainerID() = %q, %q; want %q, %q", id, runtime, tt.id, tt.runtime)
+			}
+		})
+	}
+}
+
+// containerMountInfo is /proc/<pid>/mountinfo of a devcontainer with the
+// project and a home volume bind-mounted from the host.
+const containerMountInfo = `612 540 0:48 / / rw,relatime master:1 - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/ABC
+613 612 0:52 / /proc rw,nosuid,nodev,noexec,relatime - proc proc rw
+620 612 253:1 /var/lib/docker/containers/` + testContainerID + `/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw
+621 612 253:2 /me/src/my\040app /workspaces/my\040app rw,relatime - ext4 /dev/vda2 rw
+622 612 253:1 /var/lib/docker/volumes/claude-home/_data /home/node/.claude rw,relatime - ext4 /dev/vda1 rw
+623 612 0:60 / /tmp rw - tmpfs tmpfs rw
+`
+
+// hostMountInfo is the host's mountinfo: / and a separate /home filesystem.
+const hostMountInfo = `22 1 253:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw
+45 22 253:2 / /home rw,relatime shared:30 - ext4 /dev/vda2 rw
+46 22 0:61 / /run/user/1000 rw shared:40 - tmpfs tmpfs rw
+`
+
+func TestParseMountInfo(t *testing.T) {
+	mounts := session.ParseMountInfo(containerMountInfo)
+	if len(mounts) != 6 {
+		t.Fatalf("ParseMountInfo returned %d mounts, want 6", len(mounts))
+	}
+
+	expected := session.MountInfo{Device: "253:2", Root: "/me/src/my app", MountPoint: "/workspaces/my app", FSType: "ext4"}
+	if mounts[3] != expected {
+		t.Errorf("mounts[3] = %+v, want %+v", mounts[3], expected)
+	}
+	if mounts[0].MountPoint != "/" || mounts[0].FSType != "overlay" {
+		t.Errorf("mounts[0] = %+v, want the overlay root", mounts[0])
+	}
+
+	if got := session.ParseMountInfo("garbage\n\n1 2 3\n"); len(got) != 0 {
+		t.Errorf("ParseMountInfo(garbage) = %+v, want none", got)
+	}
+}
+
+func TestBindMounts(t *testing.T) {
+	mounts := session.BindMounts(session.ParseMountInfo(containerMountInfo), session.ParseMountInfo(hostMountInfo))
+
+	// The overlay root, /proc and the container's own tmpfs have no host
+	// counterpart and are skipped
+	expected := []session.BindMount{
+		{HostPath: "/home/me/src/my app", ContainerPath: "/workspaces/my app"},
+		{HostPath: "/var/lib/docker/volumes/claude-home/_data", ContainerPath: "/home/node/.claude"},
+		{HostPath: "/var/lib/docker/containers/" + testContainerID + "/hostname", ContainerPath: "/etc/hostname"},
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Errorf("BindMounts() =\n%+v\nwant\n%+v", mounts, expected)
+	}
+}
+
+func TestContainerHostPath(t *testing.T) {
+	container := session.Container{
+		PID: 4242,
+		Mounts: []session.BindMount{
+			{HostPath: "/home/me/src/app/vendor", ContainerPath: "/workspaces/app/vendor"},
+			{HostPath: "/home/me/src/app", ContainerPath: "/workspaces/app"},
+		},
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/workspaces/app", "/home/me/src/app"},
+		{"/workspaces/app/cmd/server", "/home/me/src/app/cmd/server"},
+		{"/workspaces/app/vendor/lib", "/home/me/src/app/vendor/lib"},
+		{"/workspaces/app2", "/proc/4242/root/workspaces/app2"},
+		{"/root/.claude", "/proc/4242/root/root/.claude"},
+	}
+
+	for _, tt := range tests {
+		if got := container.HostPath(tt.path); got != tt.expected {
+			t.Errorf("HostPath(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestContainerResumeCommand(t *testing.T) {
+	s := session.Session{
+		CWD:              "/home/me/src/app",
+		SessionID:        "abc-123",
+		Container:        "devbox",
+		ContainerRuntime: "podman",
+		ContainerCWD:     "/workspaces/my app",
+	}
+	expected := "podman exec -it -w '/workspaces/my app' devbox claude --resume abc-123"
+	if got := s.ResumeCommand(); got != expected {
+		t.Errorf("ResumeCommand() = %q, want %q", got, expected)
+	}
+
+	s.ContainerRuntime = ""
+	if got := s.ResumeCommand(); got != "docker exec -it -w '/workspaces/my app' devbox claude --resume abc-123" {
+		t.Errorf("ResumeCommand() without runtime = %q, want docker exec", got)
+	}
+}
+
+func TestEnrichContainerSession(t *testing.T) {
+	// The container's ~/.claude, as mounted on the host. Its index records
+	// the transcript path as seen inside the container.
+	claudeDir := t.TempDir()
+	projectDir := filepath.Join(claudeDir, "projects", session.EncodePath("/workspaces/app"))
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := "9a8b7c6d-0000-4000-8000-000000000001"
+	transcript := `{"type":"user","message":{"role":"user","content":"fix the devcontainer build"},"cwd":"/workspaces/app","sessionId":"` + sessionID + `"}` + "\n"
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(transcript), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index := `{"entries":[{"sessionId":"` + sessionID + `","fullPath":"/home/node/.claude/projects/-workspaces-app/` + sessionID + `.jsonl","firstPrompt":"fix the devcontainer build","messageCount":1,"fileMtime":1}]}`
+	if err := os.WriteFile(filepath.Join(projectDir, "sessions-index.json"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := []session.Session{{
+		CWD:          "/home/me/src/app",
+		Container:    "devbox",
+		ContainerCWD: "/workspaces/app",
+		ClaudeDir:    claudeDir,
+	}}
+	session.EnrichSessions(sessions, filepath.Join(t.TempDir(), "host-claude"))
+
+	if sessions[0].SessionID != sessionID {
+		t.Errorf("SessionID = %q, want %q from the container's config directory", sessions[0].SessionID, sessionID)
+	}
+	if sessions[0].Topic != "fix the devcontainer build" {
+		t.Errorf("Topic = %q, want the indexed first prompt", sessions[0].Topic)
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model    string
+		tokens   int
+		expected int
+	}{
+		{"claude-sonnet-4-5-20250929", 150_000, 200_000},
+		{"sonnet[1m]", 10_000, 1_000_000},
+		{"claude-sonnet-4-5-20250929[1M]", 0, 1_000_000},
+		{"claude-sonnet-4-5-20250929", 250_000, 1_000_000},
+		{"", 0, 200_000},
+	}
+
+	for _, tt := range tests {
+		if got := session.ContextWindow(tt.model, tt.tokens); got != tt.expected {
+			t.Errorf("ContextWindow(%q, %d) = %d, want %d", tt.model, tt.tokens, got, tt.expected)
+		}
+	}
+
+	s := session.Session{ContextTokens: 170_000, ContextLimit: 200_000}
+	if s.ContextPercent() != 85 || !s.NearCompaction() {
+		t.Errorf("expected 85%% and near compaction, got %d%%", s.ContextPercent())
+	}
+	if (session.Session{}).ContextPercent() != -1 {
+		t.Error("expected -1 for a session without usage")
+	}
+}
+
+func TestTranscriptContext(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, ".claude", "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each compaction writes a boundary and a summary and counts once; the
+	// latest main-thread usage wins over a later subagent message
+	transcript := `{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Fix the login bug"}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:01:00Z","message":{"id":"msg_1","model":"claude-opus-4-1-20250805","role":"assistant","content":[],"usage":{"input_tokens":10,"output_tokens":500,"cache_creation_input_tokens":5000,"cache_read_input_tokens":150000}}}
+{"type":"system","subtype":"compact_boundary","cwd":"/Users/me/api","timestamp":"2025-02-06T10:02:00Z","content":"Conversation compacted"}
+{"type":"user","isCompactSummary":true,"cwd":"/Users/me/api","timestamp":"2025-02-06T10:02:01Z","message":{"role":"user","content":"This session is being continued from a previous conversation."}}
+{"type":"system","subtype":"compact_boundary","cwd":"/Users/me/api","timestamp":"2025-02-06T10:05:00Z","content":"Conversation compacted"}
+{"type":"user","isCompactSummary":true,"cwd":"/Users/me/api","timestamp":"2025-02-06T10:05:01Z","message":{"role":"user","content":"This session is being continued from a previous conversation."}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:06:00Z","message":{"id":"msg_2","model":"claude-opus-4-1-20250805","role":"assistant","content":[],"usage":{"input_tokens":20,"output_tokens":100,"cache_creation_input_tokens":30000,"cache_read_input_tokens":10000}}}
+{"type":"assistant","isSidechain":true,"cwd":"/Users/me/api","timestamp":"2025-02-06T10:07:00Z","message":{"id":"msg_3","model":"claude-haiku-4-5-20251001","role":"assistant","content":[],"usage":{"input_tokens":90000,"output_tokens":100,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}
+`
+	writeTestFile(t, filepath.Join(projectDir, "context-session.jsonl"), transcript)
+
+	var exited []session.Session
+	for _, s := range session.Discover(session.DiscoverOptions{RecentWindow: time.Hour}) {
+		if s.State == session.StateExited {
+			exited = append(exited, s)
+		}
+	}
+	if len(exited) != 1 {
+		t.Fatalf("expected 1 exited session, got %d", len(exited))
+	}
+
+	s := exited[0]
+	if s.ContextTokens != 40_020 {
+		t.Errorf("context tokens = %d, want 40020", s.ContextTokens)
+	}
+	if s.ContextLimit != session.DefaultContextWindow {
+		t.Errorf("context limit = %d, want %d", s.ContextLimit, session.DefaultContextWindow)
+	}
+	if s.ContextPercent() != 20 {
+		t.Errorf("context percent = %d, want 20", s.ContextPercent())
+	}
+	if s.Compactions != 2 {
+		t.Errorf("compactions = %d, want 2", s.Compactions)
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestFilesTouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, ".claude", "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := `{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Fix the login bug"}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:01:00Z","message":{"id":"msg_1","role":"assistant","content":[{"type":"tool_use","name":"Read","input":{"file_path":"/Users/me/api/README.md"}}]}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:02:00Z","message":{"id":"msg_2","role":"assistant","content":[{"type":"tool_use","name":"Edit","input":{"file_path":"/Users/me/api/auth.go","old_string":"a","new_string":"b"}}]}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:03:00Z","message":{"id":"msg_2","role":"assistant","content":[{"type":"tool_use","name":"Write","input":{"file_path":"internal/token.go","content":"package internal"}}]}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:04:00Z","message":{"id":"msg_3","role":"assistant","content":[{"type":"tool_use","name":"MultiEdit","input":{"file_path":"/Users/me/api/auth.go","edits":[]}},{"type":"tool_use","name":"NotebookEdit","input":{"notebook_path":"/Users/me/api/analysis.ipynb"}}]}}
+`
+	transcriptPath := filepath.Join(projectDir, "files-session.jsonl")
+	writeTestFile(t, transcriptPath, transcript)
+
+	var exited []session.Session
+	for _, s := range session.Discover(session.DiscoverOptions{RecentWindow: time.Hour}) {
+		if s.State == session.StateExited {
+			exited = append(exited, s)
+		}
+	}
+	if len(exited) != 1 {
+		t.Fatalf("expected 1 exited session, got %d", len(exited))
+	}
+
+	if exited[0].Lines != 5 {
+		t.Errorf("expected 5 transcript lines, got %d", exited[0].Lines)
+	}
+
+	files := exited[0].Files
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+
+	// Most recently touched first; the MultiEdit and NotebookEdit share a timestamp
+	expected := []struct {
+		path  string
+		edits int
+	}{
+		{"/Users/me/api/analysis.ipynb", 1},
+		{"/Users/me/api/auth.go", 2},
+		{"/Users/me/api/internal/token.go", 1},
+	}
+	for i, want := range expected {
+		if files[i].Path != want.path || files[i].Edits != want.edits {
+			t.Errorf("files[%d] = %+v, want %s with %d edits", i, files[i], want.path, want.edits)
+		}
+	}
+	if want := time.Date(2025, 2, 6, 10, 4, 0, 0, time.UTC); !files[1].LastTouched.Equal(want) {
+		t.Errorf("auth.go last touched %v, want %v", files[1].LastTouched, want)
+	}
+}
+
+func TestDetectConflicts(t *testing.T) {
+	now := time.Date(2025, 2, 6, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-5 * time.Minute)
+	sessions := []session.Session{
+		{PID: 100, Project: "me/api", State: session.StateActive, Files: []session.FileTouch{
+			{Path: "/Users/me/api/auth.go", LastTouched: recent},
+			{Path: "/Users/me/api/main.go", LastTouched: recent},
+			{Path: "/Users/me/api/go.mod", LastTouched: recent},
+		}},
+		{PID: 200, Project: "me/api", State: session.StateWaiting, Files: []session.FileTouch{
+			{Path: "/Users/me/api/auth.go", LastTouched: recent},
+			// Edited hours ago: both sessions touched it, but not together
+			{Path: "/Users/me/api/go.mod", LastTouched: now.Add(-3 * time.Hour)},
+		}},
+		{PID: 300, Project: "api@feat-a", State: session.StateActive, Files: []session.FileTouch{
+			// Same relative file in another worktree is a different file
+			{Path: "/Users/me/api/.worktrees/feat-a/auth.go", LastTouched: recent},
+		}},
+		{Project: "me/api", State: session.StateExited, Files: []session.FileTouch{
+			{Path: "/Users/me/api/main.go", LastTouched: recent},
+		}},
+	}
+
+	session.DetectConflicts(sessions, now)
+
+	if len(sessions[0].Conflicts) != 1 || sessions[0].Conflicts[0].Path != "/Users/me/api/auth.go" || sessions[0].Conflicts[0].OtherPID != 200 {
+		t.Errorf("session 100 conflicts = %+v", sessions[0].Conflicts)
+	}
+	if len(sessions[1].Conflicts) != 1 || sessions[1].Conflicts[0].OtherPID != 100 {
+		t.Errorf("session 200 conflicts = %+v", sessions[1].Conflicts)
+	}
+	if len(sessions[2].Conflicts) != 0 {
+		t.Errorf("worktree session conflicts = %+v, want none", sessions[2].Conflicts)
+	}
+	if len(sessions[3].Conflicts) != 0 {
+		t.Errorf("exited session conflicts = %+v, want none", sessions[3].Conflicts)
+	}
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/git"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestParseHead(t *testing.T) {
+	tests := []struct {
+		content  string
+		branch   string
+		detached bool
+		ok       bool
+	}{
+		{"ref: refs/heads/main\n", "main", false, true},
+		{"ref: refs/heads/feat/login\n", "feat/login", false, true},
+		{"3f2a9c1d8e7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f\n", "3f2a9c1", true, true},
+		{"", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			branch, detached, ok := git.ParseHead(tt.content)
+			if branch != tt.branch || detached != tt.detached || ok != tt.ok {
+				t.Errorf("ParseHead(%q) = (%q, %v, %v), want (%q, %v, %v)",
+					tt.content, branch, detached, ok, tt.branch, tt.detached, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	output := `# branch.oid 3f2a9c1d8e7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f
+# branch.head feat/login
+# branch.upstream origin/feat/login
+# branch.ab +2 -5
+1 .M N... 100644 100644 100644 abc abc internal/auth.go
+1 A. N... 000000 100644 100644 000 abc internal/token.go
+2 R. N... 100644 100644 100644 abc abc R100 new.go	old.go
+u UU N... 100644 100644 100644 100644 a b c conflict.go
+? scratch.txt
+! build/
+`
+	status := git.ParseStatus(output)
+	if status.Dirty != 5 {
+		t.Errorf("Dirty = %d, want 5", status.Dirty)
+	}
+	if status.Ahead != 2 || status.Behind != 5 {
+		t.Errorf("Ahead/Behind = %d/%d, want 2/5", status.Ahead, status.Behind)
+	}
+	if !status.HasUpstream {
+		t.Error("HasUpstream = false, want true")
+	}
+
+	clean := git.ParseStatus("# branch.oid abc\n# branch.head main\n")
+	if clean.Dirty != 0 || clean.HasUpstream {
+		t.Errorf("clean status = %+v", clean)
+	}
+}
+
+func TestFindRepo(t *testing.T) {
+	root := t.TempDir()
+
+	// Main working tree with a linked worktree registered under .git/worktrees
+	mainTree := filepath.Join(root, "api")
+	gitDir := filepath.Join(mainTree, ".git")
+	writeNestedFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	worktreeGitDir := filepath.Join(gitDir, "worktrees", "api-hotfix")
+	writeNestedFile(t, filepath.Join(worktreeGitDir, "HEAD"), "ref: refs/heads/hotfix\n")
+	writeNestedFile(t, filepath.Join(worktreeGitDir, "commondir"), "../..\n")
+
+	linkedTree := filepath.Join(root, "api-hotfix")
+	writeNestedFile(t, filepath.Join(linkedTree, ".git"), "gitdir: "+worktreeGitDir+"\n")
+
+	nested := filepath.Join(mainTree, "internal", "auth")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("main tree from subdirectory", func(t *testing.T) {
+		repo, ok := git.FindRepo(nested)
+		if !ok {
+			t.Fatal("FindRepo found no repository")
+		}
+		if repo.Root != mainTree || repo.GitDir != gitDir || repo.CommonDir != gitDir || repo.Worktree != "" {
+			t.Errorf("FindRepo = %+v", repo)
+		}
+		if repo.Name() != "api" {
+			t.Errorf("Name = %q, want api", repo.Name())
+		}
+		if branch, detached, _ := repo.Head(); branch != "main" || detached {
+			t.Errorf("Head = %q (detached %v), want main", branch, detached)
+		}
+	})
+
+	t.Run("linked worktree", func(t *testing.T) {
+		repo, ok := git.FindRepo(linkedTree)
+		if !ok {
+			t.Fatal("FindRepo found no repository")
+		}
+		if repo.Root != linkedTree || repo.GitDir != worktreeGitDir || repo.CommonDir != gitDir {
+			t.Errorf("FindRepo = %+v", repo)
+		}
+		if repo.Worktree != "api-hotfix" {
+			t.Errorf("Worktree = %q, want api-hotfix", repo.Worktree)
+		}
+		if repo.Name() != "api" {
+			t.Errorf("Name = %q, want api", repo.Name())
+		}
+		if branch, _, _ := repo.Head(); branch != "hotfix" {
+			t.Errorf("Head = %q, want hotfix", branch)
+		}
+	})
+
+	t.Run("relative gitdir", func(t *testing.T) {
+		relativeTree := filepath.Join(root, "api-relative")
+		relativeGitDir := filepath.Join(gitDir, "worktrees", "api-relative")
+		writeNestedFile(t, filepath.Join(relativeGitDir, "HEAD"), "ref: refs/heads/relative\n")
+		writeNestedFile(t, filepath.Join(relativeTree, ".git"), "gitdir: ../api/.git/worktrees/api-relative\n")
+
+		repo, ok := git.FindRepo(relativeTree)
+		if !ok || repo.GitDir != relativeGitDir || repo.Worktree != "api-relative" {
+			t.Errorf("FindRepo = %+v, %v", repo, ok)
+		}
+	})
+
+	t.Run("bare repository worktree", func(t *testing.T) {
+		bareGitDir := filepath.Join(root, "web.git")
+		bareWorktreeGitDir := filepath.Join(bareGitDir, "worktrees", "main")
+		writeNestedFile(t, filepath.Join(bareWorktreeGitDir, "HEAD"), "ref: refs/heads/main\n")
+		writeNestedFile(t, filepath.Join(bareWorktreeGitDir, "commondir"), "../..\n")
+		bareTree := filepath.Join(root, "web-main")
+		writeNestedFile(t, filepath.Join(bareTree, ".git"), "gitdir: "+bareWorktreeGitDir+"\n")
+
+		repo, ok := git.FindRepo(bareTree)
+		if !ok || repo.Name() != "web" || repo.Worktree != "main" {
+			t.Errorf("FindRepo = %+v (name %q), %v", repo, repo.Name(), ok)
+		}
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		if repo, ok := git.FindRepo(root); ok {
+			t.Errorf("FindRepo(%q) = %+v, want none", root, repo)
+		}
+	})
+}
+
+func TestWorktreeProjectName(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		cwd      string
+		expected string
+	}{
+		{"worktree root", "/src/api/.worktrees/feat-a", "/src/api/.worktrees/feat-a", "api@feat-a"},
+		{"subdirectory", "/src/api/.worktrees/feat-a", "/src/api/.worktrees/feat-a/cmd/server", "api@feat-a/cmd/server"},
+		{"outside root", "/src/api/.worktrees/feat-a", "/src/other", "api@feat-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := session.WorktreeProjectName("api", "feat-a", tt.root, tt.cwd)
+			if result != tt.expected {
+				t.Errorf("WorktreeProjectName(%q) = %q, want %q", tt.cwd, result, tt.expected)
+			}
+		})
+	}
+}
+
+// writeNestedFile writes content to path, creating parent directories.
+func writeNestedFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
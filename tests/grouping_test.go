@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/git"
+	"github.com/Jevs21/cctop/internal/grouping"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    grouping.By
+		wantErr bool
+	}{
+		{"", grouping.None, false},
+		{"none", grouping.None, false},
+		{"project", grouping.ByProject, false},
+		{"repo", grouping.ByRepo, false},
+		{"branch", grouping.ByBranch, false},
+		{"source", grouping.BySource, false},
+		{"state", grouping.ByState, false},
+		{"worktree", grouping.None, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			by, err := grouping.Parse(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if by != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.name, by, tt.want)
+			}
+		})
+	}
+}
+
+// groupFixture is sorted as the table would be: groups appear in the order
+// of their first session.
+var groupFixture = []session.Session{
+	{PID: 1, Project: "work/api", State: session.StateActive, Git: &git.Info{Repository: "api", Branch: "main"}},
+	{PID: 2, Project: "work/web", State: session.StateWaiting},
+	{PID: 3, Project: "work/api-fix", State: session.StateIdle, Git: &git.Info{Repository: "api", Branch: "fix"}},
+	{PID: 4, Project: "work/api", State: session.StateWaiting, Git: &git.Info{Repository: "api", Branch: "main"}},
+	{PID: 0, Project: "work/web", State: session.StateExited},
+}
+
+// groupPIDs lists each group's key and its sessions' PIDs.
+func groupPIDs(groups []*grouping.Group) map[string][]int {
+	pids := make(map[string][]int)
+	for _, g := range groups {
+		for _, s := range g.Sessions {
+			pids[g.Key] = append(pids[g.Key], s.PID)
+		}
+	}
+	return pids
+}
+
+func TestGroupingSplit(t *testing.T) {
+	tests := []struct {
+		by       grouping.By
+		keys     []string
+		sessions map[string][]int
+	}{
+		{
+			by:       grouping.ByProject,
+			keys:     []string{"work/api", "work/web", "work/api-fix"},
+			sessions: map[string][]int{"work/api": {1, 4}, "work/web": {2, 0}, "work/api-fix": {3}},
+		},
+		{
+			// Worktrees of one repository share a group; sessions outside a
+			// repository fall back to their project
+			by:       grouping.ByRepo,
+			keys:     []string{"api", "work/web"},
+			sessions: map[string][]int{"api": {1, 3, 4}, "work/web": {2, 0}},
+		},
+		{
+			by:       grouping.ByBranch,
+			keys:     []string{"main", "(no branch)", "fix"},
+			sessions: map[string][]int{"main": {1, 4}, "(no branch)": {2, 0}, "fix": {3}},
+		},
+		{
+			by:       grouping.ByState,
+			keys:     []string{"active", "waiting", "idle", "exited"},
+			sessions: map[string][]int{"active": {1}, "waiting": {2, 4}, "idle": {3}, "exited": {0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by.String(), func(t *testing.T) {
+			groups := grouping.Split(tt.by, groupFixture)
+			var keys []string
+			for _, g := range groups {
+				keys = append(keys, g.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("group order = %v, want %v", keys, tt.keys)
+			}
+			if pids := groupPIDs(groups); !reflect.DeepEqual(pids, tt.sessions) {
+				t.Errorf("group sessions = %v, want %v", pids, tt.sessions)
+			}
+		})
+	}
+}
+
+// rowLabels describes rows as group keys ("#key") and PIDs.
+func rowLabels(rows []grouping.Row) []any {
+	var labels []any
+	for _, row := range rows {
+		if row.IsHeader() {
+			labels = append(labels, "#"+row.Group.Key)
+		} else {
+			labels = append(labels, row.Session.PID)
+		}
+	}
+	return labels
+}
+
+func TestGroupingRows(t *testing.T) {
+	collapsed := make(grouping.Collapsed)
+
+	if rows := rowLabels(grouping.Rows(grouping.None, groupFixture, collapsed)); !reflect.DeepEqual(rows, []any{1, 2, 3, 4, 0}) {
+		t.Errorf("ungrouped rows = %v, want the sessions alone", rows)
+	}
+
+	rows := grouping.Rows(grouping.ByProject, groupFixture, collapsed)
+	expected := []any{"#work/api", 1, 4, "#work/web", 2, 0, "#work/api-fix", 3}
+	if !reflect.DeepEqual(rowLabels(rows), expected) {
+		t.Errorf("grouped rows = %v, want %v", rowLabels(rows), expected)
+	}
+
+	// Collapsing is per grouping: the project stays collapsed when grouping
+	// by something else and back
+	collapsed.Set(grouping.ByProject, "work/web", true)
+	expected = []any{"#work/api", 1, 4, "#work/web", "#work/api-fix", 3}
+	if rows := rowLabels(grouping.Rows(grouping.ByProject, groupFixture, collapsed)); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("rows with work/web collapsed = %v, want %v", rows, expected)
+	}
+	if collapsed.Has(grouping.ByRepo, "work/web") {
+		t.Error("collapsing a project also collapsed the repo group with the same key")
+	}
+}
+
+func TestGroupingSetCollapsed(t *testing.T) {
+	collapsed := make(grouping.Collapsed)
+	rows := grouping.Rows(grouping.ByProject, groupFixture, collapsed)
+
+	// Collapsing from a session row folds its group and moves the cursor to
+	// the group header
+	cursor := grouping.SetCollapsed(grouping.ByProject, rows, 5, collapsed, true)
+	if cursor != 3 || !collapsed.Has(grouping.ByProject, "work/web") {
+		t.Fatalf("collapse from row 5: cursor %d, collapsed %v; want cursor 3 on the work/web header", cursor, collapsed)
+	}
+
+	// Expanding from the header keeps the cursor there
+	rows = grouping.Rows(grouping.ByProject, groupFixture, collapsed)
+	cursor = grouping.SetCollapsed(grouping.ByProject, rows, cursor, collapsed, false)
+	if cursor != 3 || collapsed.Has(grouping.ByProject, "work/web") {
+		t.Errorf("expand on row 3: cursor %d, collapsed %v; want cursor 3 and the group expanded", cursor, collapsed)
+	}
+
+	// Collapsing a header keeps the cursor on it
+	rows = grouping.Rows(grouping.ByProject, groupFixture, collapsed)
+	if cursor := grouping.SetCollapsed(grouping.ByProject, rows, 0, collapsed, true); cursor != 0 {
+		t.Errorf("collapse on header row 0: cursor %d, want 0", cursor)
+	}
+
+	// Without grouping there is nothing to collapse
+	rows = grouping.Rows(grouping.None, groupFixture, collapsed)
+	if cursor := grouping.SetCollapsed(grouping.None, rows, 2, collapsed, true); cursor != 2 {
+		t.Errorf("collapse without grouping: cursor %d, want 2", cursor)
+	}
+}
+
+func TestGroupingToggleMarks(t *testing.T) {
+	markable := func(s session.Session) bool { return s.PID > 0 }
+	groups := grouping.Split(grouping.ByProject, groupFixture)
+	web := groups[1] // Sessions 2 and the exited 0
+
+	marked := map[int]bool{1: true}
+	grouping.ToggleMarks(web, marked, markable)
+	if !reflect.DeepEqual(marked, map[int]bool{1: true, 2: true}) {
+		t.Errorf("marks after marking work/web = %v, want 1 and 2; exited sessions are not markable", marked)
+	}
+
+	// A fully marked group is unmarked, leaving other groups' marks
+	grouping.ToggleMarks(web, marked, markable)
+	if !reflect.DeepEqual(marked, map[int]bool{1: true}) {
+		t.Errorf("marks after toggling work/web again = %v, want only 1", marked)
+	}
+
+	// A partly marked group is completed rather than cleared
+	api := groups[0]
+	grouping.ToggleMarks(api, marked, markable)
+	if !reflect.DeepEqual(marked, map[int]bool{1: true, 4: true}) {
+		t.Errorf("marks after toggling a partly marked group = %v, want 1 and 4", marked)
+	}
+}
@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/Users/me/project", "/Users/me/project"},
+		{"abc-123", "abc-123"},
+		{"", "''"},
+		{"/Users/me/my project", "'/Users/me/my project'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf)", "'$(rm -rf)'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := session.ShellQuote(tt.input)
+			if result != tt.expected {
+				t.Errorf("ShellQuote(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResumeCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		cwd       string
+		sessionID string
+		expected  string
+	}{
+		{"with cwd", "/Users/me/app", "abc-123", "cd /Users/me/app && claude --resume abc-123"},
+		{"quoted cwd", "/Users/me/my app", "abc-123", "cd '/Users/me/my app' && claude --resume abc-123"},
+		{"no cwd", "", "abc-123", "claude --resume abc-123"},
+		{"no session ID", "/Users/me/app", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := session.ResumeCommand(tt.cwd, tt.sessionID)
+			if result != tt.expected {
+				t.Errorf("ResumeCommand(%q, %q) = %q, want %q", tt.cwd, tt.sessionID, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScanHistory(t *testing.T) {
+	claudeDir := t.TempDir()
+	projectDir := filepath.Join(claudeDir, "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	olderPath := filepath.Join(projectDir, "11111111-aaaa.jsonl")
+	writeTestFile(t, olderPath, `{"type":"user","cwd":"/Users/me/api","message":{"role":"user","content":"Fix the login bug"}}
+{"type":"assistant","gitBranch":"fix/login","message":{"role":"assistant","content":"done"}}`)
+
+	newerPath := filepath.Join(projectDir, "22222222-bbbb.jsonl")
+	writeTestFile(t, newerPath, `{"type":"user","cwd":"/Users/me/api","message":{"role":"user","content":"Add rate limiting"}}`)
+
+	now := time.Now()
+	os.Chtimes(olderPath, now.Add(-2*time.Hour), now.Add(-2*time.Hour))
+	os.Chtimes(newerPath, now.Add(-time.Hour), now.Add(-time.Hour))
+
+	entries := session.ScanHistory(claudeDir)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].SessionID != "22222222-bbbb" {
+		t.Errorf("expected newest entry first, got %q", entries[0].SessionID)
+	}
+
+	older := entries[1]
+	if older.CWD != "/Users/me/api" {
+		t.Errorf("expected CWD /Users/me/api, got %q", older.CWD)
+	}
+	if older.Project != "me/api" {
+		t.Errorf("expected project me/api, got %q", older.Project)
+	}
+	if older.Topic != "Fix the login bug" {
+		t.Errorf("expected topic from first prompt, got %q", older.Topic)
+	}
+	if older.Branch != "fix/login" {
+		t.Errorf("expected branch fix/login, got %q", older.Branch)
+	}
+}
+
+func TestScanHistoryConfigDirs(t *testing.T) {
+	defaultDir, workDir := t.TempDir(), t.TempDir()
+	for _, dir := range []string{defaultDir, workDir} {
+		projectDir := filepath.Join(dir, "projects", "-Users-me-api")
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(projectDir, filepath.Base(dir)+".jsonl"),
+			`{"type":"user","cwd":"/Users/me/api","message":{"role":"user","content":"Deploy the api"}}`)
+	}
+
+	if entries := session.ScanHistory(defaultDir, workDir); len(entries) != 2 {
+		t.Errorf("expected a transcript from each config directory, got %d entries", len(entries))
+	}
+}
+
+func TestMatchHistory(t *testing.T) {
+	now := time.Now()
+	entries := []session.HistoryEntry{
+		{SessionID: "a", Project: "me/api", Topic: "Fix the login bug", Modified: now.Add(-3 * time.Hour)},
+		{SessionID: "b", Project: "me/web", Topic: "Redesign login page", Modified: now.Add(-1 * time.Hour)},
+		{SessionID: "c", Project: "me/api", Topic: "Add rate limiting", Modified: now.Add(-2 * time.Hour)},
+	}
+
+	t.Run("substring matches across fields", func(t *testing.T) {
+		matches := session.MatchHistory(entries, "api login")
+		if len(matches) != 1 || matches[0].SessionID != "a" {
+			t.Errorf("expected only session a, got %v", matches)
+		}
+	})
+
+	t.Run("ties ordered by recency", func(t *testing.T) {
+		matches := session.MatchHistory(entries, "login")
+		if len(matches) != 2 || matches[0].SessionID != "b" {
+			t.Errorf("expected b before a, got %v", matches)
+		}
+	})
+
+	t.Run("subsequence matches project", func(t *testing.T) {
+		matches := session.MatchHistory(entries, "mweb")
+		if len(matches) != 1 || matches[0].SessionID != "b" {
+			t.Errorf("expected only session b, got %v", matches)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		if matches := session.MatchHistory(entries, "kubernetes"); len(matches) != 0 {
+			t.Errorf("expected no matches, got %v", matches)
+		}
+	})
+
+	t.Run("empty query returns all", func(t *testing.T) {
+		if matches := session.MatchHistory(entries, ""); len(matches) != 3 {
+			t.Errorf("expected all entries, got %d", len(matches))
+		}
+	})
+}
+
+func TestDiscoverRecentSessions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, ".claude", "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	recentPath := filepath.Join(projectDir, "recent-session.jsonl")
+	writeTestFile(t, recentPath, `{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Fix the login bug"}}`)
+	now := time.Now()
+	os.Chtimes(recentPath, now.Add(-5*time.Minute), now.Add(-5*time.Minute))
+
+	stalePath := filepath.Join(projectDir, "stale-session.jsonl")
+	writeTestFile(t, stalePath, `{"type":"user","cwd":"/Users/me/api","message":{"role":"user","content":"Old work"}}`)
+	os.Chtimes(stalePath, now.Add(-3*time.Hour), now.Add(-3*time.Hour))
+
+	var exited []session.Session
+	for _, s := range session.Discover(session.DiscoverOptions{RecentWindow: time.Hour}) {
+		if s.State == session.StateExited {
+			exited = append(exited, s)
+		}
+	}
+
+	if len(exited) != 1 {
+		t.Fatalf("expected 1 exited session, got %d", len(exited))
+	}
+	s := exited[0]
+	if s.PID != 0 {
+		t.Errorf("expected no PID for exited session, got %d", s.PID)
+	}
+	if s.SessionID != "recent-session" || s.Topic != "Fix the login bug" || s.Project != "me/api" {
+		t.Errorf("unexpected exited session metadata: %+v", s)
+	}
+	if s.Duration <= 0 {
+		t.Errorf("expected positive duration from first timestamp, got %v", s.Duration)
+	}
+
+	for _, s := range session.Discover(session.DiscoverOptions{}) {
+		if s.State == session.StateExited {
+			t.Errorf("expected no exited sessions without a recent window, got %+v", s)
+		}
+	}
+}
+
+func TestScanHistoryUsesSessionsIndex(t *testing.T) {
+	claudeDir := t.TempDir()
+	projectDir := filepath.Join(claudeDir, "projects", "-Users-me-web")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	indexedPath := filepath.Join(projectDir, "indexed-session.jsonl")
+	writeTestFile(t, indexedPath, `{"type":"user","cwd":"/Users/me/web","message":{"role":"user","content":"transcript prompt"}}`)
+
+	unindexedPath := filepath.Join(projectDir, "unindexed-session.jsonl")
+	writeTestFile(t, unindexedPath, `{"type":"user","cwd":"/Users/me/web","message":{"role":"user","content":"Parsed prompt"}}
+{"type":"assistant","message":{"role":"assistant","content":"ok"}}`)
+
+	writeTestFile(t, filepath.Join(projectDir, "sessions-index.json"), `{
+  "originalPath": "/Users/me/web",
+  "entries": [
+    {
+      "sessionId": "indexed-session",
+      "fullPath": "`+indexedPath+`",
+      "firstPrompt": "Indexed prompt",
+      "messageCount": 42,
+      "created": "2025-02-06T10:00:00Z",
+      "modified": "2025-02-06T11:00:00Z",
+      "gitBranch": "feat/index"
+    }
+  ]
+}`)
+
+	entries := session.ScanHistory(claudeDir)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	byID := make(map[string]session.HistoryEntry)
+	for _, entry := range entries {
+		byID[entry.SessionID] = entry
+	}
+
+	indexed := byID["indexed-session"]
+	if indexed.Topic != "Indexed prompt" || indexed.Messages != 42 || indexed.Branch != "feat/index" {
+		t.Errorf("expected metadata from index, got %+v", indexed)
+	}
+	if indexed.CWD != "/Users/me/web" {
+		t.Errorf("expected CWD from index originalPath, got %q", indexed.CWD)
+	}
+	if !indexed.Created.Equal(time.Date(2025, 2, 6, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected created time from index, got %v", indexed.Created)
+	}
+	if indexed.Size == 0 {
+		t.Error("expected transcript size to be recorded")
+	}
+
+	unindexed := byID["unindexed-session"]
+	if unindexed.Topic != "Parsed prompt" || unindexed.Messages != 2 {
+		t.Errorf("expected metadata parsed from transcript, got %+v", unindexed)
+	}
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/hub"
+	"github.com/Jevs21/cctop/internal/remote"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestHubMergesAgents(t *testing.T) {
+	server := httptest.NewServer(hub.NewServer("secret"))
+	defer server.Close()
+
+	ctx := context.Background()
+	for _, snapshot := range []session.Snapshot{
+		{Hostname: "beta", Sessions: []session.Session{{PID: 7, CWD: "/srv/web"}}},
+		{Hostname: "alpha", Sessions: []session.Session{{PID: 1, CWD: "/srv/api"}, {PID: 2, CWD: "/srv/db"}}},
+	} {
+		if err := hub.Push(ctx, server.Client(), server.URL, "secret", snapshot); err != nil {
+			t.Fatalf("Push(%s) failed: %v", snapshot.Hostname, err)
+		}
+	}
+
+	view, err := hub.Fetch(ctx, server.Client(), server.URL, "secret")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(view.Agents) != 2 || view.Agents[0].Name != "alpha" || view.Agents[1].Name != "beta" {
+		t.Fatalf("Agents = %+v, want alpha then beta", view.Agents)
+	}
+	if view.Agents[0].Sessions != 2 || view.Agents[0].Stale {
+		t.Errorf("alpha = %+v, want 2 fresh sessions", view.Agents[0])
+	}
+	if len(view.Sessions) != 3 {
+		t.Fatalf("Sessions has %d entries, want 3", len(view.Sessions))
+	}
+	for _, s := range view.Sessions {
+		want := "alpha"
+		if s.PID == 7 {
+			want = "beta"
+		}
+		if s.Host != want {
+			t.Errorf("session %d Host = %q, want %q", s.PID, s.Host, want)
+		}
+	}
+
+	// A later snapshot replaces the agent's sessions
+	if err := hub.Push(ctx, server.Client(), server.URL, "secret", session.Snapshot{Hostname: "beta"}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	view, _ = hub.Fetch(ctx, server.Client(), server.URL, "secret")
+	if len(view.Sessions) != 2 {
+		t.Errorf("after beta cleared, Sessions has %d entries, want 2", len(view.Sessions))
+	}
+}
+
+func TestHubRejectsBadToken(t *testing.T) {
+	server := httptest.NewServer(hub.NewServer("secret"))
+	defer server.Close()
+
+	ctx := context.Background()
+	err := hub.Push(ctx, server.Client(), server.URL, "wrong", session.Snapshot{Hostname: "box"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Push with wrong token = %v, want 401", err)
+	}
+	if _, err := hub.Fetch(ctx, server.Client(), server.URL, ""); err == nil {
+		t.Error("Fetch without token succeeded")
+	}
+
+	resp, err := http.Get(server.URL + hub.SessionsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unauthenticated GET status = %d, want 401", resp.StatusCode)
+	}
+
+	view, _ := hub.Fetch(ctx, server.Client(), server.URL, "secret")
+	if len(view.Agents) != 0 {
+		t.Errorf("rejected push was stored: %+v", view.Agents)
+	}
+}
+
+func TestHubAgentAndClient(t *testing.T) {
+	server := httptest.NewServer(hub.NewServer("secret"))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	for _, name := range []string{"laptop", "self"} {
+		agent := hub.Agent{
+			HubURL:   server.URL,
+			Token:    "secret",
+			Name:     name,
+			Interval: 20 * time.Millisecond,
+			Discover: func() []session.Session {
+				return []session.Session{{PID: 99, CWD: "/home/dev/" + name}}
+			},
+		}
+		go func() { done <- agent.Run(ctx) }()
+	}
+	defer func() {
+		cancel()
+		<-done
+		<-done
+	}()
+
+	// Both agents have pushed once the view lists them
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		view, _ := hub.Fetch(ctx, server.Client(), server.URL, "secret")
+		if len(view.Agents) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("agents did not push within 5s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	client := hub.Connect(server.URL, "secret", "self")
+	defer client.Close()
+	client.WaitReady(5 * time.Second)
+
+	sessions := client.Sessions()
+	if len(sessions) != 1 || sessions[0].Host != "laptop" || sessions[0].CWD != "/home/dev/laptop" {
+		t.Fatalf("Sessions() = %+v, want only laptop's session", sessions)
+	}
+
+	statuses := client.Status(time.Now())
+	if len(statuses) != 1 || statuses[0].Label != "laptop" || statuses[0].State != remote.HostConnected {
+		t.Errorf("Status() = %+v, want laptop connected", statuses)
+	}
+}
+
+func TestHubClientUnreachable(t *testing.T) {
+	server := httptest.NewServer(hub.NewServer("secret"))
+	url := server.URL
+	server.Close()
+
+	client := hub.Connect(url, "secret", "")
+	defer client.Close()
+	client.WaitReady(5 * time.Second)
+
+	statuses := client.Status(time.Now())
+	if len(statuses) != 1 || statuses[0].State != remote.HostDisconnected || statuses[0].Err == "" {
+		t.Errorf("Status() = %+v, want one disconnected hub with an error", statuses)
+	}
+	if !strings.HasPrefix(statuses[0].Label, "hub ") {
+		t.Errorf("Label = %q, want the hub address", statuses[0].Label)
+	}
+}
+
+func TestHubOverTLS(t *testing.T) {
+	server := httptest.NewTLSServer(hub.NewServer("secret"))
+	defer server.Close()
+
+	ctx := context.Background()
+	snapshot := session.Snapshot{Hostname: "alpha", Sessions: []session.Session{{PID: 1, CWD: "/srv/api"}}}
+	if err := hub.Push(ctx, server.Client(), server.URL, "secret", snapshot); err != nil {
+		t.Fatalf("Push over HTTPS failed: %v", err)
+	}
+	view, err := hub.Fetch(ctx, server.Client(), server.URL, "secret")
+	if err != nil || len(view.Sessions) != 1 {
+		t.Fatalf("Fetch over HTTPS = %+v, %v; want 1 session", view, err)
+	}
+	if hub.InsecureURL(server.URL) {
+		t.Errorf("InsecureURL(%s) = true for an https URL", server.URL)
+	}
+}
+
+func TestHubInsecureURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		insecure bool
+	}{
+		{"http://monitor:7433", true},
+		{"monitor:7433", true}, // No scheme means plain HTTP
+		{"http://10.0.0.5:7433", true},
+		{"https://monitor:7433", false},
+		{"http://localhost:7433", false},
+		{"http://127.0.0.1:7433", false},
+		{"http://[::1]:7433", false},
+	}
+	for _, tt := range tests {
+		if got := hub.InsecureURL(tt.url); got != tt.insecure {
+			t.Errorf("InsecureURL(%q) = %v, want %v", tt.url, got, tt.insecure)
+		}
+	}
+
+	for addr, loopback := range map[string]bool{":7433": false, "0.0.0.0:7433": false, "127.0.0.1:7433": true, "localhost:7433": true} {
+		if got := hub.LoopbackAddr(addr); got != loopback {
+			t.Errorf("LoopbackAddr(%q) = %v, want %v", addr, got, loopback)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// writeLockFile writes an IDE lock file into claudeDir/ide. Fixtures use this
+// test's PID as the IDE's, so the IDE counts as running.
+func writeLockFile(t *testing.T, claudeDir, name, body string) {
+	t.Helper()
+	ideDir := filepath.Join(claudeDir, "ide")
+	if err := os.MkdirAll(ideDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ideDir, name), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ideFixturePS lists IDE-spawned claude processes (no terminal) plus one in
+// a terminal and a shell a session spawned, which IDE discovery must ignore.
+const ideFixturePS = `  PID   ELAPSED TTY      COMMAND
+  101     10:00 ??       /Users/me/.local/bin/claude
+  102     05:00 ??       /Users/me/.local/bin/claude --model opus
+  103     01:00 ?        /usr/local/bin/claude
+  104     02:00 ??       /Users/me/.local/bin/claude
+  105     03:00 ??       /Users/me/.local/bin/claude
+  106     04:00 ttys001  /Users/me/.local/bin/claude
+  107     00:30 ??       /bin/bash -c cd /src/app && claude mcp list
+`
+
+func TestDiscoverIDESessions(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	claudeDir := t.TempDir()
+
+	// A multi-root VS Code window and a Cursor window on a nested folder
+	writeLockFile(t, claudeDir, "37791.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/app","/src/lib/"],"ideName":"Visual Studio Code","transport":"ws"}`)
+	writeLockFile(t, claudeDir, "41002.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/app/web"],"ideName":"Cursor"}`)
+	// Lock files of closed IDEs and unreadable ones are ignored
+	writeLockFile(t, claudeDir, "50000.lock", `{"pid":0,"workspaceFolders":["/src/other"],"ideName":"Visual Studio Code"}`)
+	writeLockFile(t, claudeDir, "50001.lock", `not json`)
+
+	cwdMap := map[int]string{
+		101: "/src/app",        // first folder of the VS Code window
+		102: "/src/lib/pkg",    // second folder, in a subdirectory
+		103: "/src/app",        // second session in the same window (Linux ps)
+		104: "/src/app2",       // shares a prefix with /src/app only
+		105: "/src/app/web/ui", // inside both windows; the deeper folder wins
+		106: "/src/app",        // has a terminal, so it is a CLI session
+		107: "/src/app",        // a shell mentioning claude, not a session
+	}
+
+	sessions := session.DiscoverIDESessions(claudeDir, session.ParsePS(ideFixturePS), cwdMap)
+
+	expected := map[int]struct {
+		source     string
+		cwd        string
+		connection string
+	}{
+		101: {"VSCode", "/src/app", "ws:37791"},
+		102: {"VSCode", "/src/lib/pkg", "ws:37791"},
+		103: {"VSCode", "/src/app", "ws:37791"},
+		105: {"Cursor", "/src/app/web/ui", "ws:41002"},
+	}
+
+	if len(sessions) != len(expected) {
+		t.Fatalf("DiscoverIDESessions returned %d sessions, want %d: %+v", len(sessions), len(expected), sessions)
+	}
+	for _, s := range sessions {
+		want, ok := expected[s.PID]
+		if !ok {
+			t.Errorf("unexpected session for pid %d (cwd %q)", s.PID, s.CWD)
+			continue
+		}
+		if s.Source.Type != want.source || s.CWD != want.cwd || s.IDEConnection != want.connection {
+			t.Errorf("pid %d: source %q cwd %q connection %q, want %q %q %q",
+				s.PID, s.Source.Type, s.CWD, s.IDEConnection, want.source, want.cwd, want.connection)
+		}
+	}
+
+	if found := findPIDs(sessions, 102); found[102].Model != "opus" {
+		t.Errorf("pid 102: Model = %q, want opus from --model", found[102].Model)
+	}
+}
+
+func TestDiscoverIDESessionsTransport(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	claudeDir := t.TempDir()
+	writeLockFile(t, claudeDir, "8123.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/app"],"ideName":"Visual Studio Code","transport":"sse"}`)
+	writeLockFile(t, claudeDir, "window.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/lib"],"ideName":"Visual Studio Code"}`)
+
+	entries := session.ParsePS("  PID   ELAPSED TTY      COMMAND\n  201     01:00 ??       claude\n  202     01:00 ??       claude\n")
+	sessions := session.DiscoverIDESessions(claudeDir, entries, map[int]string{201: "/src/app", 202: "/src/lib"})
+
+	found := findPIDs(sessions, 201, 202)
+	if found[201].IDEConnection != "sse:8123" {
+		t.Errorf("IDEConnection = %q, want sse:8123", found[201].IDEConnection)
+	}
+	// A lock file not named after a port has only the transport
+	if found[202].IDEConnection != "ws" {
+		t.Errorf("IDEConnection without a port = %q, want ws", found[202].IDEConnection)
+	}
+}
+
+func TestDiscoverIDESessionsNoLockFiles(t *testing.T) {
+	entries := session.ParsePS(ideFixturePS)
+	if sessions := session.DiscoverIDESessions(t.TempDir(), entries, map[int]string{101: "/src/app"}); len(sessions) != 0 {
+		t.Errorf("DiscoverIDESessions without lock files = %+v, want none", sessions)
+	}
+}
+
+func TestLookupIDE(t *testing.T) {
+	tests := []struct {
+		ideName string
+		name    string
+	}{
+		{"Visual Studio Code", "VSCode"},
+		{"Visual Studio Code - Insiders", "VSCode"},
+		{"VSCodium", "Codium"},
+		{"Cursor", "Cursor"},
+		{"Windsurf", "Windsrf"},
+		{"Zed", "Zed"},
+		{"Neovim", "Neovim"},
+		{"nvim", "Neovim"},
+		{"IntelliJ IDEA Ultimate", "IDEA"},
+		{"PyCharm Professional", "PyCharm"},
+		{"GoLand", "GoLand"},
+		{"WebStorm", "WebStm"},
+		{"RustRover", "RustRvr"},
+		{"Android Studio", "AStudio"},
+	}
+
+	for _, tt := range tests {
+		ide, ok := session.LookupIDE(tt.ideName)
+		if !ok || ide.Name != tt.name {
+			t.Errorf("LookupIDE(%q) = %q, %v; want %q", tt.ideName, ide.Name, ok, tt.name)
+			continue
+		}
+		if len([]rune(ide.Name)) > 7 || ide.Icon == "" || ide.Color == "" {
+			t.Errorf("LookupIDE(%q) = %+v, want a name of at most 7 characters, an icon and a colour", tt.ideName, ide)
+		}
+	}
+
+	// Names merely containing a registered name are not that IDE
+	for _, ideName := range []string{"Sublime Text", "Zedd", "Provider", "override", "My Cursor Fork"} {
+		if ide, ok := session.LookupIDE(ideName); ok {
+			t.Errorf("LookupIDE(%q) = %+v, want no entry", ideName, ide)
+		}
+	}
+	if ide, _ := session.LookupIDE("nvim-qt"); ide.Name != "Neovim" {
+		t.Errorf("LookupIDE(nvim-qt) = %q, want Neovim rather than Vim", ide.Name)
+	}
+}
+
+func TestRegisterIDEs(t *testing.T) {
+	t.Cleanup(func() { session.RegisterIDEs(nil) })
+	session.RegisterIDEs([]session.IDE{
+		{Match: "sublime", Name: "Sublime", Icon: "S", Color: "208"},
+		{Match: "Cursor", Name: "Cur", Color: "99"},
+	})
+
+	// Config mappings name unknown IDEs and override built-in ones
+	if ide, ok := session.LookupIDE("Sublime Text"); !ok || ide.Name != "Sublime" {
+		t.Errorf("LookupIDE(Sublime Text) = %+v, %v; want the config mapping", ide, ok)
+	}
+	if ide, ok := session.LookupIDE("Cursor"); !ok || ide.Name != "Cur" {
+		t.Errorf("LookupIDE(Cursor) = %+v, %v; want the config mapping to win", ide, ok)
+	}
+	if ide, ok := session.IDEByName("Sublime"); !ok || ide.Color != "208" {
+		t.Errorf("IDEByName(Sublime) = %+v, %v; want the config mapping", ide, ok)
+	}
+	if ide, ok := session.IDEByName("VSCode"); !ok || ide.Icon == "" {
+		t.Errorf("IDEByName(VSCode) = %+v, %v; want the built-in entry", ide, ok)
+	}
+}
+
+func TestDiscoverIDESessionsUnknownIDE(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	claudeDir := t.TempDir()
+	writeLockFile(t, claudeDir, "9001.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/app"],"ideName":"Superlongeditor Pro"}`)
+	writeLockFile(t, claudeDir, "9002.lock", `{"pid":`+pid+`,"workspaceFolders":["/src/lib"],"ideName":""}`)
+
+	entries := session.ParsePS("  PID   ELAPSED TTY      COMMAND\n  301     01:00 ??       claude\n  302     01:00 ??       claude\n")
+	found := findPIDs(session.DiscoverIDESessions(claudeDir, entries, map[int]string{301: "/src/app", 302: "/src/lib"}), 301, 302)
+
+	// Unknown names keep their first word, cut to the SRC column
+	if found[301].Source.Type != "Superlo" {
+		t.Errorf("unknown IDE source = %q, want Superlo", found[301].Source.Type)
+	}
+	if found[302].Source.Type != "IDE" {
+		t.Errorf("unnamed IDE source = %q, want IDE", found[302].Source.Type)
+	}
+}
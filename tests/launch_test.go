@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestParseLaunchFlags(t *testing.T) {
+	tests := []struct {
+		command        string
+		model          string
+		permissionMode string
+	}{
+		{"claude", "", ""},
+		{"claude --model opus", "opus", ""},
+		{"claude --model=claude-sonnet-4-5-20250929 --resume abc", "claude-sonnet-4-5-20250929", ""},
+		{"claude --dangerously-skip-permissions", "", "bypassPermissions"},
+		{"claude --permission-mode plan -p hello", "", "plan"},
+		{"claude --permission-mode=acceptEdits --model haiku", "haiku", "acceptEdits"},
+		{"claude --model --verbose", "", ""},
+	}
+
+	for _, tt := range tests {
+		model, permissionMode := session.ParseLaunchFlags(tt.command)
+		if model != tt.model || permissionMode != tt.permissionMode {
+			t.Errorf("ParseLaunchFlags(%q) = %q, %q; want %q, %q", tt.command, model, permissionMode, tt.model, tt.permissionMode)
+		}
+	}
+}
+
+func TestShortModelName(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected string
+	}{
+		{"claude-sonnet-4-5-20250929", "sonnet-4.5"},
+		{"claude-opus-4-1-20250805", "opus-4.1"},
+		{"claude-opus-4-20250514", "opus-4"},
+		{"claude-3-5-haiku-20241022", "3-5-haiku"},
+		{"opus", "opus"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := session.ShortModelName(tt.model); got != tt.expected {
+			t.Errorf("ShortModelName(%q) = %q, want %q", tt.model, got, tt.expected)
+		}
+	}
+}
+
+func TestTranscriptModelAndMode(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	projectDir := filepath.Join(home, ".claude", "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mode switches to bypass mid-session and a synthetic message does
+	// not replace the real model
+	transcript := `{"type":"user","cwd":"/Users/me/api","permissionMode":"default","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Fix the login bug"}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:01:00Z","message":{"id":"msg_1","model":"claude-opus-4-1-20250805","role":"assistant","content":[{"type":"text","text":"ok"}]}}
+{"type":"user","cwd":"/Users/me/api","permissionMode":"bypassPermissions","timestamp":"2025-02-06T10:02:00Z","message":{"role":"user","content":"go ahead"}}
+{"type":"assistant","cwd":"/Users/me/api","timestamp":"2025-02-06T10:03:00Z","message":{"id":"msg_2","model":"<synthetic>","role":"assistant","content":[{"type":"text","text":"No response requested."}]}}
+`
+	writeTestFile(t, filepath.Join(projectDir, "mode-session.jsonl"), transcript)
+
+	var exited []session.Session
+	for _, s := range session.Discover(session.DiscoverOptions{RecentWindow: time.Hour}) {
+		if s.State == session.StateExited {
+			exited = append(exited, s)
+		}
+	}
+	if len(exited) != 1 {
+		t.Fatalf("expected 1 exited session, got %d", len(exited))
+	}
+
+	if exited[0].Model != "claude-opus-4-1-20250805" {
+		t.Errorf("model = %q, want claude-opus-4-1-20250805", exited[0].Model)
+	}
+	if !exited[0].PermissionsBypassed() {
+		t.Errorf("permission mode = %q, want bypassPermissions", exited[0].PermissionMode)
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/git"
+	"github.com/Jevs21/cctop/internal/session"
+	"github.com/Jevs21/cctop/internal/tui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// layoutFixture fills every optional column with values at their widest.
+var layoutFixture = []session.Session{
+	{
+		PID: 101, State: session.StateActive, Source: session.Source{Type: "VSCode"},
+		Project: "work/a-rather-long-project-name", Topic: "Refactor the session table layout so rows never overflow the terminal",
+		Git:   &git.Info{Repository: "cctop", Branch: "feature/priority-column-dropping", Dirty: 12, Ahead: 3, Behind: 1, HasUpstream: true, HasStatus: true},
+		Model: "claude-sonnet-4-5", PermissionMode: "bypassPermissions", ContextTokens: 150_000, ContextLimit: 200_000,
+		CPUPercent: 123.4, Memory: 1 << 30, Processes: 4, Duration: 3*time.Hour + 25*time.Minute,
+		LastActivity: time.Now(),
+	},
+	{
+		PID: 102, State: session.StateWaiting, Source: session.Source{Type: "CLI"},
+		Project: "home/notes", Topic: "Short", Branch: "main",
+		Model: "claude-opus-4-1", PermissionMode: "plan", Duration: 90 * time.Second,
+		LastActivity: time.Now(),
+	},
+}
+
+func TestRenderFitsWidth(t *testing.T) {
+	for _, width := range []int{60, 80, 100, 110, 120} {
+		out := tui.Render(tui.Options{}, layoutFixture, width)
+		for i, line := range strings.Split(out, "\n") {
+			if w := lipgloss.Width(line); w > width {
+				t.Errorf("width %d: line %d is %d columns wide: %q", width, i, w, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+// shareDir makes a t.TempDir directory, and the per-test directory above it,
+// reachable by other users.
+func shareDir(t *testing.T, dir string) {
+	t.Helper()
+	for _, d := range []string{filepath.Dir(dir), dir} {
+		if err := os.Chmod(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// startFakeClaudeAs runs a fake claude in dir as the given UID.
+func startFakeClaudeAs(t *testing.T, dir string, uid int) int {
+	t.Helper()
+	cmd := fakeClaudeCommand(t, dir)
+	shareDir(t, filepath.Dir(cmd.Path))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(uid)}}
+	startCommand(t, cmd)
+	return cmd.Process.Pid
+}
+
+func TestDiscoverAllUsers(t *testing.T) {
+	if runtime.GOOS != "linux" || os.Getuid() != 0 {
+		t.Skip("starting processes as other users needs root on Linux")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no nobody account")
+	}
+	nobodyUID, _ := strconv.Atoi(nobody.Uid)
+
+	// A UID without a passwd entry has no known home, so its transcripts
+	// cannot be located
+	unknownUID := 54321
+	if _, err := user.LookupId(strconv.Itoa(unknownUID)); err == nil {
+		t.Skipf("uid %d unexpectedly exists", unknownUID)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(session.ConfigDirEnv, "")
+	nobodyProject, _ := filepath.EvalSymlinks(t.TempDir())
+	unknownProject, _ := filepath.EvalSymlinks(t.TempDir())
+	shareDir(t, nobodyProject)
+	shareDir(t, unknownProject)
+
+	nobodyPID := startFakeClaudeAs(t, nobodyProject, nobodyUID)
+	unknownPID := startFakeClaudeAs(t, unknownProject, unknownUID)
+
+	var sessions []session.Session
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		sessions = session.Discover(session.DiscoverOptions{AllUsers: true})
+		if len(findPIDs(sessions, nobodyPID, unknownPID)) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	found := findPIDs(sessions, nobodyPID, unknownPID)
+	if len(found) != 2 {
+		t.Fatalf("--all-users discovered %d of the 2 other users' sessions", len(found))
+	}
+
+	s := found[nobodyPID]
+	if s.User != nobody.Username || s.ClaudeDir != filepath.Join(nobody.HomeDir, ".claude") || s.NoAccess {
+		t.Errorf("nobody's session: User %q ClaudeDir %q NoAccess %v, want %q %q false",
+			s.User, s.ClaudeDir, s.NoAccess, nobody.Username, filepath.Join(nobody.HomeDir, ".claude"))
+	}
+
+	s = found[unknownPID]
+	if s.User != strconv.Itoa(unknownUID) || !s.NoAccess || s.Topic != "" {
+		t.Errorf("unknown user's session: User %q NoAccess %v Topic %q, want %q, no access and no topic",
+			s.User, s.NoAccess, s.Topic, strconv.Itoa(unknownUID))
+	}
+
+	// By default only the current user's sessions are listed
+	sessions = session.Discover(session.DiscoverOptions{})
+	if found := findPIDs(sessions, nobodyPID, unknownPID); len(found) != 0 {
+		t.Errorf("default discovery listed %d other users' sessions, want none", len(found))
+	}
+}
@@ -0,0 +1,218 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/process"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestClassifySignalError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected process.SignalResult
+	}{
+		{"nil error", nil, process.SignalSent},
+		{"process done", os.ErrProcessDone, process.SignalAlreadyExited},
+		{"no such process", syscall.ESRCH, process.SignalAlreadyExited},
+		{"not permitted", syscall.EPERM, process.SignalPermissionDenied},
+		{"other", errors.New("boom"), process.SignalFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := process.ClassifySignalError(tt.err)
+			if result != tt.expected {
+				t.Errorf("ClassifySignalError(%v) = %v, want %v", tt.err, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSignalExitedProcess(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run helper process: %v", err)
+	}
+
+	result := process.Signal(cmd.Process.Pid, syscall.SIGINT)
+	if result != process.SignalAlreadyExited {
+		t.Errorf("expected already exited for reaped process, got %v", result)
+	}
+}
+
+func TestParseStat(t *testing.T) {
+	// comm may contain spaces and parentheses; fields are counted from the
+	// last ')'. utime 250 + stime 50 ticks, started 1000 ticks after boot.
+	stat := "4242 (tmux: server (1)) S 4000 4242 4242 0 -1 4194560 1000 0 0 0 250 50 0 0 20 0 1 0 1000 25000000 3000 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0"
+	p, err := process.ParseStat(stat, 60*time.Second)
+	if err != nil {
+		t.Fatalf("ParseStat error: %v", err)
+	}
+	expected := process.Process{PID: 4242, PPID: 4000, Name: "tmux: server (1)", CPUTime: 3 * time.Second, Runtime: 50 * time.Second}
+	if p != expected {
+		t.Errorf("ParseStat() = %+v, want %+v", p, expected)
+	}
+
+	for _, bad := range []string{"", "4242 tmux S 1", "4242 (x) S 1 2 3"} {
+		if _, err := process.ParseStat(bad, 0); err == nil {
+			t.Errorf("ParseStat(%q) accepted a malformed line", bad)
+		}
+	}
+}
+
+func TestParseStatm(t *testing.T) {
+	if got := process.ParseStatm("25000 3000 800 10 0 2000 0\n", 4096); got != 3000*4096 {
+		t.Errorf("ParseStatm() = %d, want %d", got, 3000*4096)
+	}
+	if got := process.ParseStatm("garbage", 4096); got != 0 {
+		t.Errorf("ParseStatm(garbage) = %d, want 0", got)
+	}
+}
+
+func TestParsePSProcesses(t *testing.T) {
+	output := `    1     0   0.0  12000 3-04:05:06    0:10.50 /sbin/launchd
+ 4242     1  12.5 310000      01:02    1:02.25 /Users/me/.local/bin/claude --resume abc
+ 4250  4242  97.0 1048576      00:30    0:29.00 node /Users/me/app/node_modules/.bin/vite
+`
+	procs := process.ParsePSProcesses(output)
+	if len(procs) != 3 {
+		t.Fatalf("ParsePSProcesses returned %d processes, want 3", len(procs))
+	}
+
+	expected := process.Process{
+		PID:        4242,
+		PPID:       1,
+		Name:       "claude",
+		Command:    "/Users/me/.local/bin/claude --resume abc",
+		CPUTime:    62250 * time.Millisecond,
+		CPUPercent: 12.5,
+		RSS:        310000 * 1024,
+		Runtime:    62 * time.Second,
+	}
+	if procs[1] != expected {
+		t.Errorf("procs[1] = %+v, want %+v", procs[1], expected)
+	}
+	if want := 3*24*time.Hour + 4*time.Hour + 5*time.Minute + 6*time.Second; procs[0].Runtime != want {
+		t.Errorf("launchd runtime = %v, want %v", procs[0].Runtime, want)
+	}
+}
+
+func TestProcessTable(t *testing.T) {
+	table := process.NewTable([]process.Process{
+		{PID: 1, PPID: 0},
+		{PID: 100, PPID: 1, Name: "claude"},
+		{PID: 120, PPID: 100, Name: "node"},
+		{PID: 110, PPID: 100, Name: "zsh"},
+		{PID: 111, PPID: 110, Name: "npm"},
+		{PID: 200, PPID: 1, Name: "other"},
+	})
+
+	var children []int
+	for _, p := range table.Children(100) {
+		children = append(children, p.PID)
+	}
+	if !reflect.DeepEqual(children, []int{110, 120}) {
+		t.Errorf("Children(100) = %v, want [110 120]", children)
+	}
+
+	var descendants []int
+	for _, p := range table.Descendants(100) {
+		descendants = append(descendants, p.PID)
+	}
+	if !reflect.DeepEqual(descendants, []int{110, 111, 120}) {
+		t.Errorf("Descendants(100) = %v, want depth-first [110 111 120]", descendants)
+	}
+
+	if _, ok := table.Get(999); ok {
+		t.Error("Get(999) found a process")
+	}
+}
+
+func TestSamplerCPUPercent(t *testing.T) {
+	cpu := map[int]time.Duration{100: 2 * time.Second, 200: 0}
+	sampler := process.NewSamplerFunc(func() ([]process.Process, error) {
+		return []process.Process{
+			{PID: 100, CPUTime: cpu[100], Runtime: 10 * time.Second},
+			{PID: 200, CPUTime: cpu[200], Runtime: 10 * time.Second},
+		}, nil
+	})
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first sample uses each process's lifetime average
+	table, err := sampler.Sample(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := table.Get(100); p.CPUPercent != 20 {
+		t.Errorf("first sample CPUPercent = %v, want the lifetime average 20", p.CPUPercent)
+	}
+
+	// Later samples use the CPU time spent since the previous one
+	cpu[100] += 1500 * time.Millisecond
+	cpu[200] += 500 * time.Millisecond
+	table, _ = sampler.Sample(start.Add(time.Second))
+	if p, _ := table.Get(100); p.CPUPercent != 150 {
+		t.Errorf("CPUPercent = %v, want 150 (1.5 cores)", p.CPUPercent)
+	}
+	if p, _ := table.Get(200); p.CPUPercent != 50 {
+		t.Errorf("CPUPercent = %v, want 50", p.CPUPercent)
+	}
+}
+
+func TestReadProcessesIncludesSelf(t *testing.T) {
+	procs, err := process.ReadProcesses()
+	if errors.Is(err, process.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("ReadProcesses error: %v", err)
+	}
+	self, ok := process.NewTable(procs).Get(os.Getpid())
+	if !ok {
+		t.Fatal("ReadProcesses does not list this test process")
+	}
+	if self.PPID != os.Getppid() || self.RSS <= 0 || self.Command == "" {
+		t.Errorf("self = %+v, want PPID %d, a resident size and a command line", self, os.Getppid())
+	}
+}
+
+func TestAttachResources(t *testing.T) {
+	table := process.NewTable([]process.Process{
+		{PID: 1, PPID: 0},
+		{PID: 100, PPID: 1, Name: "claude", CPUPercent: 12, RSS: 300 << 20},
+		{PID: 110, PPID: 100, Name: "zsh", CPUPercent: 0.5, RSS: 4 << 20},
+		{PID: 111, PPID: 110, Name: "npm", CPUPercent: 80, RSS: 700 << 20},
+		{PID: 200, PPID: 1, Name: "claude", CPUPercent: 1, RSS: 200 << 20},
+	})
+	sessions := []session.Session{
+		{PID: 100},
+		{PID: 200, Host: "build-box", CPUPercent: 7, Memory: 1 << 20, Processes: 1},
+		{PID: 300},
+		{SessionID: "exited"},
+	}
+
+	session.AttachResources(sessions, table)
+
+	// The claude process and everything below it are summed
+	if s := sessions[0]; s.CPUPercent != 92.5 || s.Memory != 1004<<20 || s.Processes != 3 {
+		t.Errorf("local session = %.1f%% %d bytes in %d processes, want 92.5%% %d bytes in 3", s.CPUPercent, s.Memory, s.Processes, 1004<<20)
+	}
+	// Remote sessions keep what their host reported, even on a matching PID
+	if s := sessions[1]; s.CPUPercent != 7 || s.Memory != 1<<20 || s.Processes != 1 {
+		t.Errorf("remote session = %+v, want its reported resources", s)
+	}
+	// Sessions whose process is gone have none
+	for _, s := range sessions[2:] {
+		if s.CPUPercent != 0 || s.Memory != 0 || s.Processes != 0 {
+			t.Errorf("session without a process (pid %d) = %.1f%% %d bytes in %d processes, want none", s.PID, s.CPUPercent, s.Memory, s.Processes)
+		}
+	}
+}
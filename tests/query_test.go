@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/query"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := session.Session{
+		PID:          4242,
+		User:         "alice",
+		State:        session.StateWaiting,
+		Source:       session.Source{Type: "CLI"},
+		Project:      "work/api-server",
+		Topic:        "Fix login handler",
+		Branch:       "feat/login",
+		CWD:          "/home/me/work/api-server",
+		Duration:     25 * time.Minute,
+		Messages:     40,
+		LastActivity: now.Add(-3 * time.Minute),
+		Usage:        session.Usage{InputTokens: 90_000, OutputTokens: 20_000},
+
+		Model:          "claude-sonnet-4-5-20250929",
+		PermissionMode: session.PermissionBypass,
+		ContextTokens:  170_000,
+		ContextLimit:   200_000,
+		Compactions:    1,
+
+		CPUPercent: 35,
+		Memory:     600_000_000,
+		Processes:  3,
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"", true},
+		{"state:waiting", true},
+		{"state:idle", false},
+		{"STATE:Wait", true},
+		{"state=wait", false},
+		{"src:cli", true},
+		{"source:vscode", false},
+		{"project:api*", false},
+		{"project:*api*", true},
+		{"project:work/api*", true},
+		{"branch:feat/", true},
+		{"branch:feat/*", true},
+		{"age>10m", true},
+		{"age>1h", false},
+		{"dur<=25m", true},
+		{"age<1d", true},
+		{"idle>2m", true},
+		{"idle>5m", false},
+		{"tokens>100k", true},
+		{"tokens>=0.2M", false},
+		{"msgs=40", true},
+		{"pid:4242", true},
+		{"user:alice", true},
+		{"user:bob", false},
+		{"model:sonnet", true},
+		{"model:opus", false},
+		{"mode:bypass", true},
+		{"mode=plan", false},
+		{"ctx>=80", true},
+		{"ctx<50", false},
+		{"compactions>0", true},
+		{"cpu>=30", true},
+		{"cpu>50", false},
+		{"mem>500M", true},
+		{"memory>1000M", false},
+		{"procs=3", true},
+		{"login", true},
+		{"logout", false},
+		{"-topic:refactor", true},
+		{"-topic:login", false},
+		{"NOT state:waiting", false},
+		{"state:waiting src:cli project:*api* branch:feat/ age>10m tokens>100k -topic:refactor", true},
+		{"state:idle OR state:waiting", true},
+		{"state:idle OR state:active", false},
+		{"state:idle state:waiting OR src:cli", true},
+		{"state:idle (state:waiting OR src:cli)", false},
+		{"state:waiting AND (src:vscode OR tokens>1k)", true},
+		{`topic:"login handler"`, true},
+		{`topic:"login  handler"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := query.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.input, err)
+			}
+			if result := q.MatchAt(s, now); result != tt.expected {
+				t.Errorf("Parse(%q).Match = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueryParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{"colour:red", 0},
+		{"state:waiting age>soon", 18},
+		{"tokens>lots", 7},
+		{"project>api", 7},
+		{"state:", 6},
+		{"(state:idle", 11},
+		{"state:idle)", 10},
+		{"state:idle OR", 13},
+		{`topic:"open`, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := query.Parse(tt.input)
+			var parseErr *query.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", tt.input, err)
+			}
+			if parseErr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error position = %d, want %d (%v)", tt.input, parseErr.Pos, tt.pos, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/remote"
+)
+
+// scriptDialer runs a shell script per target in place of ssh.
+func scriptDialer(scripts map[string]string) remote.Dialer {
+	return func(ctx context.Context, target string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", scripts[target])
+	}
+}
+
+func TestPoolMergesRemoteSessions(t *testing.T) {
+	snapshot := `{"generated_at":"2025-01-01T00:00:00Z","hostname":"box","sessions":[{"pid":42,"cwd":"/srv/api","state":"waiting"}]}`
+	pool := remote.Start([]string{"me@box"}, scriptDialer(map[string]string{
+		"me@box": "printf '%s\\n' '" + snapshot + "'; sleep 30",
+	}))
+	defer pool.Close()
+
+	pool.WaitReady(5 * time.Second)
+
+	sessions := pool.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("Sessions() returned %d sessions, want 1", len(sessions))
+	}
+	if sessions[0].Host != "me@box" || sessions[0].PID != 42 || sessions[0].CWD != "/srv/api" {
+		t.Errorf("session = host %q pid %d cwd %q, want me@box 42 /srv/api", sessions[0].Host, sessions[0].PID, sessions[0].CWD)
+	}
+
+	status := pool.Status(time.Now())[0]
+	if status.State != remote.HostConnected || status.Label != "box" || status.Sessions != 1 {
+		t.Errorf("status = %v %q %d sessions, want connected box 1", status.State, status.Label, status.Sessions)
+	}
+}
+
+func TestPoolReportsDisconnect(t *testing.T) {
+	pool := remote.Start([]string{"down"}, scriptDialer(map[string]string{
+		"down": "echo 'ssh: connect to host down port 22: Connection refused' >&2; exit 255",
+	}))
+
+	start := time.Now()
+	pool.WaitReady(5 * time.Second)
+	status := pool.Status(time.Now())[0]
+	pool.Close()
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("WaitReady did not return after the agent failed")
+	}
+	if status.State != remote.HostDisconnected {
+		t.Fatalf("State = %v, want disconnected", status.State)
+	}
+	if !strings.Contains(status.Err, "Connection refused") {
+		t.Errorf("Err = %q, want the ssh error", status.Err)
+	}
+	if status.RetryAt.IsZero() {
+		t.Error("RetryAt not set after disconnect")
+	}
+	if status.Failures != 1 {
+		t.Errorf("Failures = %d, want 1", status.Failures)
+	}
+	if len(pool.Sessions()) != 0 {
+		t.Error("disconnected host still reports sessions")
+	}
+}
+
+func TestSSHDialerEndsOptions(t *testing.T) {
+	cmd := remote.SSHDialer("cctop")(context.Background(), "-oProxyCommand=touch /tmp/pwned")
+	args := cmd.Args[1:]
+	if len(args) < 3 || args[len(args)-3] != "--" || args[len(args)-2] != "-oProxyCommand=touch /tmp/pwned" {
+		t.Errorf("ssh args = %q, want the target after --", args)
+	}
+}
+
+func TestHostLabel(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{"box", "box"},
+		{"me@box", "box"},
+		{"me@box.example.com", "box.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := remote.HostLabel(tt.target); got != tt.expected {
+			t.Errorf("HostLabel(%q) = %q, want %q", tt.target, got, tt.expected)
+		}
+	}
+}
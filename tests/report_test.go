@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jevs21/cctop/internal/report"
+	"github.com/Jevs21/cctop/internal/session"
+)
+
+func TestReportBuild(t *testing.T) {
+	claudeDir := t.TempDir()
+	apiDir := filepath.Join(claudeDir, "projects", "-Users-me-work-api")
+	webDir := filepath.Join(claudeDir, "projects", "-Users-me-work-web")
+	for _, dir := range []string{apiDir, webDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The assistant reply to the first prompt is split across two lines
+	// sharing one message ID; the human answers five minutes later. The
+	// line before the window is ignored.
+	api := `{"type":"user","cwd":"/Users/me/work/api","timestamp":"2025-02-01T09:00:00Z","message":{"role":"user","content":"old prompt"}}
+{"type":"user","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Fix the login bug"}}
+{"type":"assistant","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:01:00Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","role":"assistant","content":[{"type":"text","text":"Looking"}],"usage":{"input_tokens":1000,"output_tokens":500,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}
+{"type":"assistant","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:01:05Z","message":{"id":"msg_1","model":"claude-sonnet-4-5-20250929","role":"assistant","content":[{"type":"tool_use","name":"Read","input":{}}],"usage":{"input_tokens":1000,"output_tokens":500,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}
+{"type":"user","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:01:10Z","message":{"role":"user","content":[{"type":"tool_result","content":"file"}]}}
+{"type":"assistant","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:02:00Z","message":{"id":"msg_2","model":"claude-sonnet-4-5-20250929","role":"assistant","content":[{"type":"tool_use","name":"Edit","input":{}},{"type":"tool_use","name":"Read","input":{}}],"usage":{"input_tokens":2000,"output_tokens":1000,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}
+{"type":"user","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:02:30Z","isMeta":true,"message":{"role":"user","content":"Caveat: injected"}}
+{"type":"user","cwd":"/Users/me/work/api","timestamp":"2025-02-06T10:07:00Z","message":{"role":"user","content":[{"type":"text","text":"thanks"}]}}
+`
+	web := `{"type":"user","cwd":"/Users/me/work/web","timestamp":"2025-02-07T12:00:00Z","message":{"role":"user","content":"Add dark mode"}}
+{"type":"assistant","cwd":"/Users/me/work/web","timestamp":"2025-02-07T12:03:00Z","message":{"id":"msg_3","model":"mystery-model","role":"assistant","content":[{"type":"tool_use","name":"Bash","input":{}}],"usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}
+`
+	stale := `{"type":"user","cwd":"/Users/me/work/web","timestamp":"2025-01-01T12:00:00Z","message":{"role":"user","content":"ancient"}}
+`
+	writeTestFile(t, filepath.Join(apiDir, "api-session.jsonl"), api)
+	writeTestFile(t, filepath.Join(webDir, "web-session.jsonl"), web)
+	stalePath := filepath.Join(webDir, "stale-session.jsonl")
+	writeTestFile(t, stalePath, stale)
+	old := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(stalePath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	result, err := report.Build([]string{claudeDir}, since, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Sessions != 2 {
+		t.Fatalf("expected 2 sessions, got %d", result.Sessions)
+	}
+	if want := (session.Usage{InputTokens: 3010, OutputTokens: 1520}); result.Usage != want {
+		t.Errorf("usage = %+v, want %+v", result.Usage, want)
+	}
+	if want := 5 * time.Minute; result.WaitingOnHuman != want {
+		t.Errorf("waiting on human = %v, want %v", result.WaitingOnHuman, want)
+	}
+	if want := 5 * time.Minute; result.AverageDuration != want {
+		t.Errorf("average duration = %v, want %v", result.AverageDuration, want)
+	}
+
+	// Sonnet: 3000 input at $3/M plus 1500 output at $15/M
+	if want := 0.0315; math.Abs(result.Cost-want) > 1e-9 {
+		t.Errorf("cost = %v, want %v", result.Cost, want)
+	}
+	if len(result.Unpriced) != 1 || result.Unpriced[0] != "mystery-model" {
+		t.Errorf("unpriced = %v, want [mystery-model]", result.Unpriced)
+	}
+
+	if len(result.Models) != 2 || result.Models[0].Model != "claude-sonnet-4-5-20250929" || result.Models[0].Messages != 2 {
+		t.Errorf("models = %+v", result.Models)
+	}
+	if len(result.Projects) != 2 || result.Projects[0].Project != "work/api" || result.Projects[1].Project != "work/web" {
+		t.Errorf("projects = %+v", result.Projects)
+	}
+	if len(result.Days) != 2 || result.Days[0].Project != "work/api" || result.Days[0].Sessions != 1 {
+		t.Errorf("days = %+v", result.Days)
+	}
+
+	wantTools := []report.ToolStats{{Name: "Read", Calls: 2}, {Name: "Bash", Calls: 1}, {Name: "Edit", Calls: 1}}
+	if len(result.Tools) != len(wantTools) {
+		t.Fatalf("tools = %+v, want %+v", result.Tools, wantTools)
+	}
+	for i, want := range wantTools {
+		if result.Tools[i] != want {
+			t.Errorf("tools[%d] = %+v, want %+v", i, result.Tools[i], want)
+		}
+	}
+
+	for _, format := range report.Formats {
+		var out bytes.Buffer
+		if err := report.Write(&out, result, format); err != nil {
+			t.Errorf("Write(%s): %v", format, err)
+		}
+		if !strings.Contains(out.String(), "work/api") {
+			t.Errorf("Write(%s) output missing project:\n%s", format, out.String())
+		}
+	}
+	if err := report.Write(&bytes.Buffer{}, result, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestReportParseSince(t *testing.T) {
+	now := time.Date(2025, 2, 8, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"7d", now.AddDate(0, 0, -7)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2025-01-31", time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := report.ParseSince(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "week", "-3d", "2025-13-01"} {
+		if _, err := report.ParseSince(input, now); err == nil {
+			t.Errorf("ParseSince(%q): expected error", input)
+		}
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	usage := session.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheCreationTokens: 1_000_000, CacheReadTokens: 1_000_000}
+	tests := []struct {
+		model  string
+		want   float64
+		priced bool
+	}{
+		{"claude-opus-4-1-20250805", 15 + 75 + 18.75 + 1.50, true},
+		{"claude-opus-4-5-20251101", 5 + 25 + 6.25 + 0.50, true},
+		{"claude-sonnet-4-5-20250929", 3 + 15 + 3.75 + 0.30, true},
+		{"claude-haiku-4-5-20251001", 1 + 5 + 1.25 + 0.10, true},
+		{"unknown", 0, false},
+	}
+	for _, tt := range tests {
+		got, priced := report.EstimateCost(tt.model, usage)
+		if priced != tt.priced || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("EstimateCost(%q) = %v, %v; want %v, %v", tt.model, got, priced, tt.want, tt.priced)
+		}
+	}
+}
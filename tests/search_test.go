@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Jevs21/cctop/internal/search"
+)
+
+func TestSearchCache(t *testing.T) {
+	claudeDir := t.TempDir()
+	projectDir := filepath.Join(claudeDir, "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	transcriptPath := filepath.Join(projectDir, "session-one.jsonl")
+	writeTestFile(t, transcriptPath, `{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Why does the Login handler panic?"}}
+{"type":"assistant","timestamp":"2025-02-06T10:00:05Z","message":{"role":"assistant","content":[{"type":"text","text":"Let me look."},{"type":"tool_use","name":"Read","input":{"file_path":"/Users/me/api/handlers/login.go"}}]}}
+{"type":"assistant","timestamp":"2025-02-06T10:00:09Z","message":{"role":"assistant","content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./handlers/..."}}]}}
+`)
+
+	cachePath := filepath.Join(t.TempDir(), "cache.gob")
+	cache := search.Open(cachePath)
+
+	changed, err := cache.Update(claudeDir)
+	if err != nil || !changed {
+		t.Fatalf("expected initial update to change the cache, got changed=%v err=%v", changed, err)
+	}
+
+	t.Run("matches prompts case-insensitively", func(t *testing.T) {
+		results := cache.Search("handler panic", 0)
+		if len(results) != 1 || results[0].Kind != "user" {
+			t.Fatalf("expected 1 user match, got %+v", results)
+		}
+		if results[0].Project != "me/api" || results[0].SessionID != "session-one" {
+			t.Errorf("unexpected result metadata: %+v", results[0])
+		}
+		matched := results[0].Snippet[results[0].MatchStart:results[0].MatchEnd]
+		if !strings.EqualFold(matched, "handler") {
+			t.Errorf("expected highlighted match %q, got %q", "handler", matched)
+		}
+	})
+
+	t.Run("matches tool inputs", func(t *testing.T) {
+		results := cache.Search("handlers/login.go", 0)
+		if len(results) != 1 || results[0].Kind != "Read" {
+			t.Fatalf("expected 1 Read match, got %+v", results)
+		}
+		results = cache.Search("go test", 0)
+		if len(results) != 1 || results[0].Kind != "Bash" {
+			t.Fatalf("expected 1 Bash match, got %+v", results)
+		}
+	})
+
+	t.Run("newest first", func(t *testing.T) {
+		results := cache.Search("login", 0)
+		if len(results) != 2 || results[0].Kind != "Read" {
+			t.Fatalf("expected Read match before user match, got %+v", results)
+		}
+	})
+
+	t.Run("unchanged files are skipped", func(t *testing.T) {
+		changed, err := cache.Update(claudeDir)
+		if err != nil || changed {
+			t.Errorf("expected no change, got changed=%v err=%v", changed, err)
+		}
+	})
+
+	t.Run("appended lines are read incrementally", func(t *testing.T) {
+		file, err := os.OpenFile(transcriptPath, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString(`{"type":"user","timestamp":"2025-02-06T10:05:00Z","message":{"role":"user","content":"now fix the logout flow"}}` + "\n")
+		file.Close()
+
+		if _, err := cache.Update(claudeDir); err != nil {
+			t.Fatal(err)
+		}
+		if results := cache.Search("logout", 0); len(results) != 1 {
+			t.Errorf("expected appended line to be searchable, got %+v", results)
+		}
+		if results := cache.Search("handler panic", 0); len(results) != 1 {
+			t.Errorf("expected earlier documents to be kept once, got %d", len(results))
+		}
+	})
+
+	t.Run("persists across reopen", func(t *testing.T) {
+		if err := cache.Save(); err != nil {
+			t.Fatal(err)
+		}
+		reopened := search.Open(cachePath)
+		if results := reopened.Search("logout", 0); len(results) != 1 {
+			t.Errorf("expected reopened cache to contain documents, got %+v", results)
+		}
+		changed, _ := reopened.Update(claudeDir)
+		if changed {
+			t.Error("expected reopened cache to be up to date")
+		}
+	})
+
+	t.Run("deleted transcripts are dropped", func(t *testing.T) {
+		os.Remove(transcriptPath)
+		changed, _ := cache.Update(claudeDir)
+		if !changed {
+			t.Error("expected deletion to change the cache")
+		}
+		if results := cache.Search("login", 0); len(results) != 0 {
+			t.Errorf("expected no results after deletion, got %+v", results)
+		}
+	})
+}
+
+func TestSearchCacheTruncatesOnCharacterBoundary(t *testing.T) {
+	claudeDir := t.TempDir()
+	projectDir := filepath.Join(claudeDir, "projects", "-Users-me-api")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// One ASCII byte puts the 8000-byte cap in the middle of a two-byte é
+	prompt := "x" + strings.Repeat("é", 5000)
+	writeTestFile(t, filepath.Join(projectDir, "long.jsonl"),
+		`{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"`+prompt+`"}}`+"\n")
+
+	cache := search.Open(filepath.Join(t.TempDir(), "cache.gob"))
+	if _, err := cache.Update(claudeDir); err != nil {
+		t.Fatal(err)
+	}
+
+	results := cache.Search("xé", 0)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 match, got %+v", results)
+	}
+	if snippet := results[0].Snippet; !utf8.ValidString(snippet) || len(snippet) != 7999 {
+		t.Errorf("cached text is %d bytes, valid UTF-8 %v; want 7999 bytes cut before the split character", len(snippet), utf8.ValidString(snippet))
+	}
+}
+
+func TestSearchCacheConfigDirs(t *testing.T) {
+	defaultDir, workDir := t.TempDir(), t.TempDir()
+	for _, dir := range []string{defaultDir, workDir} {
+		projectDir := filepath.Join(dir, "projects", "-Users-me-api")
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(projectDir, filepath.Base(dir)+".jsonl"),
+			`{"type":"user","cwd":"/Users/me/api","timestamp":"2025-02-06T10:00:00Z","message":{"role":"user","content":"Deploy the api"}}`+"\n")
+	}
+
+	cache := search.Open(filepath.Join(t.TempDir(), "cache.gob"))
+	if _, err := cache.Update(defaultDir, workDir); err != nil {
+		t.Fatal(err)
+	}
+	if results := cache.Search("deploy", 0); len(results) != 2 {
+		t.Errorf("expected a match in each config directory, got %+v", results)
+	}
+
+	// Transcripts of directories no longer searched are dropped
+	if changed, _ := cache.Update(defaultDir); !changed {
+		t.Error("expected dropping a config directory to change the cache")
+	}
+	if results := cache.Search("deploy", 0); len(results) != 1 {
+		t.Errorf("expected only the default directory's match, got %+v", results)
+	}
+}
@@ -133,6 +133,29 @@ func TestFormatDuration(t *testing.T) {
 	}
 }
 
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{1024, "1.0K"},
+		{4300, "4.2K"},
+		{13 * 1024 * 1024, "13M"},
+		{1200 * 1024 * 1024, "1.2G"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := session.FormatSize(tt.bytes)
+			if result != tt.expected {
+				t.Errorf("FormatSize(%d) = %q, want %q", tt.bytes, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestParsePS(t *testing.T) {
 	sampleOutput := `  PID   ELAPSED TTY      COMMAND
  1234     10:30 ttys001  /usr/local/bin/claude --help
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Jevs21/cctop/internal/terminal"
+)
+
+func TestParseTmuxPanes(t *testing.T) {
+	output := `/dev/ttys001 %0
+/dev/ttys004 %3
+
+/dev/pts/2 %12
+malformed
+`
+	panes := terminal.ParseTmuxPanes(output)
+
+	expected := map[string]string{
+		"/dev/ttys001": "%0",
+		"/dev/ttys004": "%3",
+		"/dev/pts/2":   "%12",
+	}
+	if len(panes) != len(expected) {
+		t.Fatalf("expected %d panes, got %d: %v", len(expected), len(panes), panes)
+	}
+	for tty, paneID := range expected {
+		if panes[tty] != paneID {
+			t.Errorf("pane for %q = %q, want %q", tty, panes[tty], paneID)
+		}
+	}
+}
+
+func TestDevicePath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ttys001", "/dev/ttys001"},
+		{"pts/3", "/dev/pts/3"},
+		{"/dev/pts/3", "/dev/pts/3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := terminal.DevicePath(tt.input)
+			if result != tt.expected {
+				t.Errorf("DevicePath(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocateRejectsNonTerminal(t *testing.T) {
+	for _, tty := range []string{"", "??", "?"} {
+		if _, err := terminal.Locate(tty); err != terminal.ErrNoPane {
+			t.Errorf("Locate(%q) error = %v, want ErrNoPane", tty, err)
+		}
+	}
+}
+
+func TestClipboardSequence(t *testing.T) {
+	if seq := terminal.ClipboardSequence("hi", false); seq != "\x1b]52;c;aGk=\x07" {
+		t.Errorf("unexpected OSC 52 sequence %q", seq)
+	}
+	if seq := terminal.ClipboardSequence("hi", true); seq != "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\" {
+		t.Errorf("unexpected tmux-wrapped sequence %q", seq)
+	}
+}